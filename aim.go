@@ -0,0 +1,92 @@
+package ctxmenu
+
+import (
+	"image"
+	"time"
+)
+
+/*
+ * Each pointer move tests whether the new position lies in the triangle between the previous
+ * position and the two corners of the open submenu facing the menu.
+ * If it does, selecting the item under the pointer, which closes the submenu, is delayed until
+ * the pointer has rested for aimTimeout (300ms) or moves outside the triangle.
+ */
+
+/* time a submenu stays open while the pointer heads into it */
+const aimTimeout = 300 * time.Millisecond
+
+/* hover action waiting for a delay to pass */
+type hoverIntent struct {
+	pending  func()
+	deadline time.Time
+}
+
+/* deferHover runs fn after delay, replacing a pending hover action */
+func (ctxmenu *ContextMenu) deferHover(delay time.Duration, fn func()) {
+	ctxmenu.hover.pending = fn
+	ctxmenu.hover.deadline = time.Now().Add(delay)
+}
+
+/* cancelHover drops the pending hover action */
+func (ctxmenu *ContextMenu) cancelHover() {
+	ctxmenu.hover.pending = nil
+}
+
+/* pollHover runs the pending hover action if its delay has passed */
+func (ctxmenu *ContextMenu) pollHover() {
+	hover := &ctxmenu.hover
+	if hover.pending == nil || time.Now().Before(hover.deadline) {
+		return
+	}
+	fn := hover.pending
+	hover.pending = nil
+	fn()
+}
+
+/* hoverTimeout shortens timeout in milliseconds to the time left until the pending hover action is due */
+func (ctxmenu *ContextMenu) hoverTimeout(timeout int) int {
+	hover := &ctxmenu.hover
+	if hover.pending == nil {
+		return timeout
+	}
+	return max(min(timeout, int(time.Until(hover.deadline).Milliseconds())+1), 1)
+}
+
+/* get the open submenu of menu, nil if none */
+func (menu *Menu[T]) openSubmenu() *Menu[T] {
+	for _, item := range menu.items {
+		if item.submenu != nil && item.submenu.mapped {
+			return item.submenu
+		}
+	}
+	return nil
+}
+
+/* aiming reports whether the pointer moving from from to to on item heads into the open submenu of menu,
+ * positions are on the screen */
+func (menu *Menu[T]) aiming(item *Item[T], from, to image.Point) bool {
+	sub := menu.openSubmenu()
+	if sub == nil || sub == item.submenu || from == to {
+		return false
+	}
+
+	/* the edge of the submenu facing menu */
+	x := sub.x
+	if sub.x < menu.x {
+		x = sub.x + sub.w
+	}
+	top := image.Point{x, sub.y}
+	bottom := image.Point{x, sub.y + sub.h}
+	return inTriangle(to, from, top, bottom)
+}
+
+/* inTriangle reports whether p lies inside the triangle a, b, c */
+func inTriangle(p, a, b, c image.Point) bool {
+	cross := func(o, u, v image.Point) int {
+		return (u.X-o.X)*(v.Y-o.Y) - (u.Y-o.Y)*(v.X-o.X)
+	}
+	d1, d2, d3 := cross(p, a, b), cross(p, b, c), cross(p, c, a)
+	negative := d1 < 0 || d2 < 0 || d3 < 0
+	positive := d1 > 0 || d2 > 0 || d3 > 0
+	return !(negative && positive)
+}
@@ -0,0 +1,163 @@
+package ctxmenu
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+type Animation int
+
+/* flags for the transitions of menus opening and closing */
+const (
+	AnimateFade  Animation = 1 << iota /* fade in and out, needs a compositor */
+	AnimateSlide                       /* slide in from the side the menu opens to */
+)
+
+type Easing int
+
+/* enum for the progress of an animation over time */
+const (
+	EaseOut   Easing = iota /* fast start, slow end */
+	EaseInOut               /* slow start and end */
+	EaseLinear
+)
+
+/* the time between two frames of an animation */
+const frameInterval = 16 * time.Millisecond
+
+/* apply the easing function to t between 0 and 1 */
+func (easing Easing) ease(t float64) float64 {
+	switch easing {
+	case EaseOut:
+		return 1 - math.Pow(1-t, 3)
+	case EaseInOut:
+		if t < 0.5 {
+			return 4 * t * t * t
+		}
+		return 1 - math.Pow(-2*t+2, 3)/2
+	default:
+		return t
+	}
+}
+
+/* animation of a window, stepped by the event loop */
+type animation struct {
+	win   *sdl.Window
+	start time.Time
+	step  func(t float64) /* apply the state at t, eased from 0 to 1 */
+	done  func()          /* called after the last step, may be nil */
+}
+
+/* animated reports whether menus are animated */
+func (ctxmenu *ContextMenu) animated() bool {
+	return ctxmenu.Animation != 0 && ctxmenu.AnimationDuration > 0
+}
+
+/* start animating win, replacing a running animation of win without finishing it */
+func (ctxmenu *ContextMenu) animate(win *sdl.Window, step func(t float64), done func()) {
+	ctxmenu.stopAnimation(win)
+	step(0)
+	ctxmenu.animations = append(ctxmenu.animations, &animation{win, time.Now(), step, done})
+}
+
+/* stop the animation of win, reports whether one was running */
+func (ctxmenu *ContextMenu) stopAnimation(win *sdl.Window) bool {
+	n := len(ctxmenu.animations)
+	ctxmenu.animations = slices.DeleteFunc(ctxmenu.animations, func(anim *animation) bool {
+		return anim.win == win
+	})
+	return len(ctxmenu.animations) < n
+}
+
+/* stepAnimations advances all animations to the current time */
+func (ctxmenu *ContextMenu) stepAnimations() {
+	var finished []*animation
+	for _, anim := range ctxmenu.animations {
+		t := min(float64(time.Since(anim.start))/float64(ctxmenu.AnimationDuration), 1)
+		anim.step(ctxmenu.Easing.ease(t))
+		if t == 1 {
+			finished = append(finished, anim)
+		}
+	}
+	for _, anim := range finished {
+		ctxmenu.stopAnimation(anim.win)
+		if anim.done != nil {
+			anim.done()
+		}
+	}
+}
+
+/* playAnimations steps all animations until they have ended, blocking the caller */
+func (ctxmenu *ContextMenu) playAnimations() {
+	for len(ctxmenu.animations) > 0 {
+		ctxmenu.stepAnimations()
+		sdl.Delay(uint32(frameInterval.Milliseconds()))
+	}
+}
+
+/* animationTimeout shortens timeout in milliseconds to the next frame while animating */
+func (ctxmenu *ContextMenu) animationTimeout(timeout int) int {
+	if len(ctxmenu.animations) == 0 {
+		return timeout
+	}
+	return min(timeout, int(frameInterval.Milliseconds()))
+}
+
+/* get a step of the transition of menu, at 0 the menu is hidden and at 1 it is shown */
+func (menu *Menu[T]) transition() func(t float64) {
+	ctxmenu := menu.ctxmenu
+	opacity := ctxmenu.opacity()
+	x, y := menu.x, menu.y
+
+	/* submenus slide in from their caller, the root menu from above */
+	dx, dy := 0, -ctxmenu.font.Metrics().Height.Ceil()
+	if menu.caller != nil {
+		dx, dy = -dy, 0
+		if menu.x >= menu.caller.x {
+			dx = -dx
+		}
+	}
+
+	return func(t float64) {
+		if ctxmenu.Animation&AnimateFade != 0 {
+			menu.win.SetWindowOpacity(opacity * float32(t))
+		}
+		if ctxmenu.Animation&AnimateSlide != 0 {
+			menu.win.SetPosition(int32(x+int(float64(dx)*(1-t))), int32(y+int(float64(dy)*(1-t))))
+		}
+	}
+}
+
+/* show the window of menu, in the first step of the transition if animated */
+func (menu *Menu[T]) animateOpen() {
+	if menu.ctxmenu.animated() {
+		menu.ctxmenu.animate(menu.win, menu.transition(), nil)
+	}
+	menu.win.Show()
+}
+
+/* skip a running transition of menu to its end, at the position of the new layout */
+func (menu *Menu[T]) animateRelayout() {
+	if menu.ctxmenu.stopAnimation(menu.win) {
+		menu.transition()(1)
+	}
+}
+
+/* animate menu closing, the window is hidden at the end */
+func (menu *Menu[T]) animateClose() {
+	if !menu.ctxmenu.animated() {
+		menu.win.Hide()
+		return
+	}
+	step := menu.transition()
+	menu.ctxmenu.animate(menu.win, func(t float64) { step(1 - t) }, menu.win.Hide)
+}
+
+/* close menu and its submenus, returning after their transitions have played */
+func (menu *Menu[T]) close() {
+	menu.hide()
+	menu.ctxmenu.playAnimations()
+}
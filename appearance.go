@@ -0,0 +1,173 @@
+package ctxmenu
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+/* time to wait for the settings portal, it is asked before the menu is shown */
+const portalTimeout = 500 * time.Millisecond
+
+type Appearance int
+
+/* enum for the color scheme preferred by the desktop, values as used by the settings portal */
+const (
+	AppearanceDefault Appearance = iota /* no preference */
+	AppearanceDark
+	AppearanceLight
+)
+
+/* PreferredAppearance asks the desktop whether dark or light colors are preferred,
+ * by the settings portal on the session bus or else by the GTK settings */
+func PreferredAppearance() Appearance {
+	if appearance, err := portalAppearance(); err == nil && appearance != AppearanceDefault {
+		return appearance
+	}
+	if appearance, err := gtkAppearance(); err == nil {
+		return appearance
+	}
+	return AppearanceDefault
+}
+
+/* PrefersReducedMotion asks the desktop whether animations are disabled,
+ * by the settings portal on the session bus or else by the GTK settings */
+func PrefersReducedMotion() bool {
+	if value, err := readPortal("org.gnome.desktop.interface", "enable-animations"); err == nil {
+		enabled, ok := value.(bool)
+		return ok && !enabled
+	}
+	if settings, err := gtkSettings(); err == nil {
+		value := strings.ToLower(settings["gtk-enable-animations"])
+		return value == "0" || value == "false"
+	}
+	return false
+}
+
+/* read org.freedesktop.appearance color-scheme from the settings portal */
+func portalAppearance() (Appearance, error) {
+	value, err := readPortal("org.freedesktop.appearance", "color-scheme")
+	if err != nil {
+		return AppearanceDefault, err
+	}
+	n, ok := value.(uint32)
+	if !ok || n > uint32(AppearanceLight) {
+		return AppearanceDefault, fmt.Errorf("invalid color-scheme: %v", value)
+	}
+	return Appearance(n), nil
+}
+
+/* read a setting from the settings portal on the session bus */
+func readPortal(namespace, key string) (any, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), portalTimeout)
+	defer cancel()
+	conn, err := dbus.ConnectSessionBus(dbus.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	/* ReadOne is missing in portals before version 2, Read wraps the value in another variant */
+	portal := conn.Object("org.freedesktop.portal.Desktop", "/org/freedesktop/portal/desktop")
+	var value dbus.Variant
+	for _, method := range []string{"ReadOne", "Read"} {
+		err = portal.CallWithContext(ctx, "org.freedesktop.portal.Settings."+method, 0, namespace, key).Store(&value)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	for {
+		inner, ok := value.Value().(dbus.Variant)
+		if !ok {
+			return value.Value(), nil
+		}
+		value = inner
+	}
+}
+
+/* read gtk-application-prefer-dark-theme and gtk-theme-name from the GTK settings */
+func gtkAppearance() (Appearance, error) {
+	settings, err := gtkSettings()
+	if err != nil {
+		return AppearanceDefault, err
+	}
+	if dark := strings.ToLower(settings["gtk-application-prefer-dark-theme"]); dark == "1" || dark == "true" {
+		return AppearanceDark, nil
+	}
+	if theme := strings.ToLower(settings["gtk-theme-name"]); strings.HasSuffix(theme, "-dark") || strings.HasSuffix(theme, ":dark") {
+		return AppearanceDark, nil
+	}
+	return AppearanceLight, nil
+}
+
+/* read the settings.ini of GTK 4, or of GTK 3 if missing */
+func gtkSettings() (map[string]string, error) {
+	config := userDir("XDG_CONFIG_HOME", ".config")
+	var err error
+	for _, version := range []string{"gtk-4.0", "gtk-3.0"} {
+		var settings map[string]string
+		settings, err = readINI(filepath.Join(config, version, "settings.ini"))
+		if err == nil {
+			return settings, nil
+		}
+	}
+	return nil, err
+}
+
+/* read the "key=value" lines of an ini-file, sections are ignored */
+func readINI(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	settings := make(map[string]string)
+	scan := bufio.NewScanner(file)
+	for scan.Scan() {
+		key, value, ok := strings.Cut(scan.Text(), "=")
+		if ok {
+			settings[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return settings, scan.Err()
+}
+
+/* SetColors replaces the colors of conf by the colors of from, theme variables are merged
+ * and the shadow is kept unless from has one */
+func (conf *Config) SetColors(from Config) {
+	conf.BackgroundColor = from.BackgroundColor
+	conf.ForegroundColor = from.ForegroundColor
+	conf.SelbackgroundColor = from.SelbackgroundColor
+	conf.SelforegroundColor = from.SelforegroundColor
+	conf.SeparatorColor = from.SeparatorColor
+	conf.DisabledColor = from.DisabledColor
+	conf.BorderColor = from.BorderColor
+	if from.ShadowColor != "" {
+		conf.ShadowColor = from.ShadowColor
+	}
+	if len(from.Colors) > 0 && conf.Colors == nil {
+		conf.Colors = make(map[string]string)
+	}
+	for name, value := range from.Colors {
+		conf.Colors[name] = value
+	}
+}
+
+/* ChooseColors sets the colors of conf to light or dark, following the preference of the desktop */
+func (conf *Config) ChooseColors(light, dark Config) {
+	if PreferredAppearance() == AppearanceDark {
+		conf.SetColors(dark)
+	} else {
+		conf.SetColors(light)
+	}
+}
@@ -0,0 +1,111 @@
+package ctxmenu
+
+import (
+	"bufio"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+/* fakeSettings implements org.freedesktop.portal.Settings version 2 */
+type fakeSettings map[string]any
+
+func (s fakeSettings) ReadOne(namespace, key string) (dbus.Variant, *dbus.Error) {
+	value, ok := s[namespace+"."+key]
+	if !ok {
+		return dbus.Variant{}, dbus.NewError("org.freedesktop.portal.Error.NotFound", []any{"not found"})
+	}
+	return dbus.MakeVariant(value), nil
+}
+
+/* fakeSettingsV1 implements org.freedesktop.portal.Settings version 1, without ReadOne */
+type fakeSettingsV1 struct {
+	settings fakeSettings
+}
+
+func (s fakeSettingsV1) Read(namespace, key string) (dbus.Variant, *dbus.Error) {
+	value, err := s.settings.ReadOne(namespace, key)
+	if err != nil {
+		return value, err
+	}
+	return dbus.MakeVariant(value), nil
+}
+
+/* startPortal starts a private session bus serving settings as the settings portal */
+func startPortal(t *testing.T, settings any) {
+	t.Helper()
+	if _, err := exec.LookPath("dbus-daemon"); err != nil {
+		t.Skip("dbus-daemon not found")
+	}
+	daemon := exec.Command("dbus-daemon", "--session", "--nofork", "--print-address")
+	stdout, err := daemon.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := daemon.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		daemon.Process.Kill()
+		daemon.Wait()
+	})
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", strings.TrimSpace(address))
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir()) /* no GTK settings to fall back to */
+
+	if settings == nil {
+		return
+	}
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if err := conn.Export(settings, "/org/freedesktop/portal/desktop", "org.freedesktop.portal.Settings"); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := conn.RequestName("org.freedesktop.portal.Desktop", dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("cannot own org.freedesktop.portal.Desktop: %v", err)
+	}
+}
+
+func TestPortal(t *testing.T) {
+	settings := fakeSettings{
+		"org.freedesktop.appearance.color-scheme":       uint32(AppearanceDark),
+		"org.gnome.desktop.interface.enable-animations": false,
+	}
+	tests := []struct {
+		name    string
+		portal  any
+		want    Appearance
+		reduced bool
+		wantErr bool
+	}{
+		{"v2", settings, AppearanceDark, true, false},
+		{"v1", fakeSettingsV1{fakeSettings{"org.freedesktop.appearance.color-scheme": uint32(AppearanceLight)}}, AppearanceLight, false, false},
+		{"invalid", fakeSettings{"org.freedesktop.appearance.color-scheme": "dark"}, AppearanceDefault, false, true},
+		{"missing", fakeSettings{}, AppearanceDefault, false, true},
+		{"no portal", nil, AppearanceDefault, false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			startPortal(t, test.portal)
+			got, err := portalAppearance()
+			if (err != nil) != test.wantErr {
+				t.Errorf("portalAppearance() error = %v, want error %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("portalAppearance() = %d, want %d", got, test.want)
+			}
+			if reduced := PrefersReducedMotion(); reduced != test.reduced {
+				t.Errorf("PrefersReducedMotion() = %v, want %v", reduced, test.reduced)
+			}
+		})
+	}
+}
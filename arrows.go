@@ -1,39 +1,106 @@
 package ctxmenu
 
-import "image"
-
-var rightArrow = &image.Alpha{Pix: []uint8{
-	0x00, 0x00, 0x00, 0xff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0xff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-}, Stride: 10, Rect: image.Rect(0x00, 0x00, 10, 7)}
-
-var topArrow = &image.Alpha{Pix: []uint8{
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0xff, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0xff, 0x00, 0x00,
-	0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00,
-	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-}, Stride: 7, Rect: image.Rect(0x00, 0x00, 7, 10)}
-
-var bottomArrow = &image.Alpha{Pix: []uint8{
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
-	0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0xff, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0xff, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-}, Stride: 7, Rect: image.Rect(0x00, 0x00, 7, 10)}
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+
+	"golang.org/x/image/vector"
+)
+
+type ArrowStyle int
+
+/* enum for the shape of submenu- and overflow-arrows */
+const (
+	ArrowTriangle ArrowStyle = iota /* filled triangle */
+	ArrowChevron                    /* open chevron */
+	ArrowGlyph                      /* glyphs from the font, see Config.ArrowGlyphs */
+)
+
+type arrowDirection int
+
+const (
+	arrowRight arrowDirection = iota
+	arrowUp
+	arrowDown
+)
+
+/* default glyphs for ArrowGlyph: right, up and down */
+const defaultArrowGlyphs = "▸▴▾"
+
+/* default arrow size relative to the font height */
+const defaultArrowScale = 0.5
+
+/* outlines of a right-pointing arrow in unit-coordinates */
+var arrowOutlines = map[ArrowStyle][][2]float32{
+	ArrowTriangle: {{0, 0}, {1, 0.5}, {0, 1}},
+	ArrowChevron:  {{0, 0}, {0.35, 0}, {1, 0.5}, {0.35, 1}, {0, 1}, {0.65, 0.5}},
+}
+
+/* rasterize fills the polygons given in unit-coordinates into an anti-aliased mask of w*h pixels */
+func rasterize(w, h int, outlines ...[][2]float32) *image.Alpha {
+	r := vector.NewRasterizer(w, h)
+	r.DrawOp = draw.Src
+	for _, outline := range outlines {
+		for i, pt := range outline {
+			if i == 0 {
+				r.MoveTo(pt[0]*float32(w), pt[1]*float32(h))
+			} else {
+				r.LineTo(pt[0]*float32(w), pt[1]*float32(h))
+			}
+		}
+		r.ClosePath()
+	}
+
+	mask := image.NewAlpha(image.Rect(0, 0, w, h))
+	r.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+	return mask
+}
+
+/* makeArrow renders an arrow pointing in dir, sized relative to the font height */
+func (ctxmenu *ContextMenu) makeArrow(dir arrowDirection) (*image.Alpha, error) {
+	if ctxmenu.ArrowStyle == ArrowGlyph {
+		glyphs := []rune(ctxmenu.ArrowGlyphs)
+		if len(glyphs) == 0 {
+			glyphs = []rune(defaultArrowGlyphs)
+		}
+		if len(glyphs) != 3 {
+			return nil, fmt.Errorf("invalid arrow glyphs: %q", ctxmenu.ArrowGlyphs)
+		}
+		text := string(glyphs[dir])
+		mask := image.NewAlpha(image.Rect(0, 0, ctxmenu.messureText(text), ctxmenu.font.Metrics().Height.Ceil()))
+		ctxmenu.drawText(mask, text)
+		return mask, nil
+	}
+
+	outline, ok := arrowOutlines[ctxmenu.ArrowStyle]
+	if !ok {
+		return nil, fmt.Errorf("invalid arrow style: %d", ctxmenu.ArrowStyle)
+	}
+
+	scale := ctxmenu.ArrowScale
+	if scale <= 0 {
+		scale = defaultArrowScale
+	}
+	long := max(int(math.Round(float64(ctxmenu.font.Metrics().Height.Ceil())*scale)), 4)
+	short := max(int(math.Round(float64(long)*0.6)), 2)
+
+	/* rotate the right-pointing outline into the requested direction */
+	rotated := make([][2]float32, len(outline))
+	for i, pt := range outline {
+		switch dir {
+		case arrowRight:
+			rotated[i] = pt
+		case arrowUp:
+			rotated[i] = [2]float32{pt[1], 1 - pt[0]}
+		case arrowDown:
+			rotated[i] = [2]float32{pt[1], pt[0]}
+		}
+	}
+
+	if dir == arrowRight {
+		return rasterize(short, long, rotated), nil
+	}
+	return rasterize(long, short, rotated), nil
+}
@@ -1,39 +1,112 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"strings"
+	"time"
 
 	"github.com/friedelschoen/ctxmenu"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
 func main() {
+	keepOpen := flag.Bool("k", false, "keep the menu open after toggling check- and radio-items")
+	multiSelect := flag.Bool("m", false, "mark several items, Enter confirms and prints all marked items")
+	globalSearch := flag.Bool("g", false, "typing in the root menu searches all submenus")
+	follow := flag.Bool("s", false, "keep reading commands from stdin after a line \".\" to update the open menu")
+	columns := flag.Bool("c", false, "wrap menus taller than the screen into columns instead of scrolling")
+	animate := flag.Bool("a", false, "fade and slide menus in and out, unless the desktop prefers reduced motion")
+	style := flag.String("style", "", "stylesheet overriding colors, padding and fonts")
+	appearance := flag.String("appearance", "auto", "colors to use: light, dark or auto to follow the desktop")
+	themeName := flag.String("theme", "", "use a bundled color theme instead of following the desktop, see -list-themes")
+	listThemes := flag.Bool("list-themes", false, "print the names of the bundled color themes and exit")
+	scheme := flag.String("colors", "", "import a color scheme: wal, base16:FILE or gtk, optionally followed by :FILE")
+	flag.Parse()
+
+	if *listThemes {
+		for _, name := range ctxmenu.ThemeNames() {
+			fmt.Println(name)
+		}
+		return
+	}
+
+	var animation ctxmenu.Animation
+	if *animate {
+		animation = ctxmenu.AnimateFade | ctxmenu.AnimateSlide
+	}
+
+	layout := ctxmenu.LayoutScroll
+	if *columns {
+		layout = ctxmenu.LayoutColumns
+	}
+
 	sdl.VideoInit("")
 
-	xmenu, err := ctxmenu.XmenuInit(ctxmenu.Config{
+	/* colors are set below by the theme */
+	config := ctxmenu.Config{
 		/* font, separate different fonts with comma */
 		FontName: "monospace:size=12",
 
-		/* colors */
-		BackgroundColor:    "#FFFFFF",
-		ForegroundColor:    "#2E3436",
-		SelbackgroundColor: "#3584E4",
-		SelforegroundColor: "#FFFFFF",
-		SeparatorColor:     "#CDC7C2",
-		BorderColor:        "#E6E6E6",
+		/* font of header items, regular font in bold if empty */
+		HeaderFontName: "monospace:size=12:bold",
 
 		/* sizes in pixels */
 		MinItemWidth:    130, /* minimum width of a menu */
+		MaxItemWidth:    400, /* maximum width of an item, longer labels are truncated */
 		BorderSize:      1,   /* menu border */
 		SeperatorLength: 3,   /* space around separator */
+		CornerRadius:    0,   /* rounded corners, 0 for square */
+		ShadowOffset:    3,   /* drop shadow to the bottom right, set ShadowColor like "#00000060" to enable */
+		ShadowBlur:      4,   /* blur of the drop shadow */
+
+		/* where to truncate long labels, set to EllipsisEnd or EllipsisMiddle */
+		Ellipsis: ctxmenu.EllipsisEnd,
 
 		/* text alignment, set to LeftAlignment, CenterAlignment or RightAlignment */
 		Alignment: ctxmenu.AlignLeft,
 
+		/* underline a unique accelerator key, typed with alt, in labels without '&'-marker;
+		 * explicit '&'-markers fire on plain typing, all other typing filters the menu */
+		AutoMnemonics: true,
+
+		/* keep the menu open after toggling check- and radio-items */
+		KeepOpen: *keepOpen,
+
+		/* mark items by clicking or Space, Enter confirms all marked items */
+		MultiSelect: *multiSelect,
+
+		/* typing in the root menu searches the items of all submenus */
+		GlobalSearch: *globalSearch,
+
+		/* opacity of the menu, needs a compositor */
+		Opacity: 1,
+
+		/* transitions of opening and closing menus, AnimateFade and AnimateSlide */
+		Animation:         animation,
+		AnimationDuration: 120 * time.Millisecond,
+		Easing:            ctxmenu.EaseOut, /* EaseOut, EaseInOut or EaseLinear */
+
+		/* stylesheet overriding the settings above per item, state and depth */
+		Stylesheet: *style,
+
+		/* hover time before the tooltip of an item is shown */
+		TooltipDelay: 500 * time.Millisecond,
+
+		/* hover time before a submenu opens, 0 opens it at once */
+		HoverDelay: 0,
+
+		/* time a PIPE:-command may take to generate a submenu */
+		PipeTimeout: 5 * time.Second,
+
+		/* menus taller than the screen, set to LayoutScroll or LayoutColumns */
+		Layout: layout,
+
+		/* shape of arrows, set to ArrowTriangle, ArrowChevron or ArrowGlyph */
+		ArrowStyle: ctxmenu.ArrowTriangle,
+		ArrowScale: 0.5, /* arrow size relative to the font height */
+
 		/*
 		 * The variables below cannot be set by X resources.
 		 * Their values must be less than .height_pixels.
@@ -45,56 +118,70 @@ func main() {
 		/* area around the icon, the triangle and the separator */
 		PaddingX: 4,
 		PaddingY: 4,
-	})
+	}
+	/* colors of the light and dark appearance, see -list-themes */
+	light, dark := ctxmenu.Themes["adwaita"], ctxmenu.Themes["adwaita-dark"]
+	switch {
+	case *themeName != "":
+		theme, ok := ctxmenu.Themes[*themeName]
+		if !ok {
+			log.Fatalln("unknown theme:", *themeName)
+		}
+		config.SetColors(theme)
+	case *appearance == "light":
+		config.SetColors(light)
+	case *appearance == "dark":
+		config.SetColors(dark)
+	case *appearance == "auto":
+		config.ChooseColors(light, dark)
+	default:
+		log.Fatalln("invalid appearance:", *appearance)
+	}
+	if *scheme != "" {
+		if err := ctxmenu.LoadScheme(&config, *scheme); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	xmenu, err := ctxmenu.XmenuInit(config)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
 	rootmenu := ctxmenu.MakeMenu[string](xmenu)
 
-	scan := bufio.NewScanner(os.Stdin)
-	delim := '\t'
-	for scan.Scan() {
-		text := []rune(scan.Text())
-
-		var depth int
-		for len(text) > 0 && text[0] == delim {
-			depth++
-			text = text[1:]
-		}
-		var label, output, imgpath string
-		var fields []string
-		for f := range strings.SplitSeq(string(text), string(delim)) {
-			if f != "" {
-				fields = append(fields, f)
-			}
-		}
-		switch len(fields) {
-		case 0:
-			/* do nothing */
-		case 1:
-			label = fields[0]
-			output = fields[0]
-		case 2:
-			label = fields[0]
-			output = fields[1]
-		case 3:
-			imgpath = fields[0]
-			imgpath = strings.TrimPrefix(imgpath, "IMG:")
-			label = fields[1]
-			output = fields[2]
-		default:
-			panic("too many fields: " + string(text))
-		}
-		if err := rootmenu.Append(label, output, imgpath, depth); err != nil {
+	if *follow {
+		commands, err := ctxmenu.Stream(rootmenu, os.Stdin)
+		if err != nil {
 			panic(err)
 		}
+		go func() {
+			err := commands(func(err error) {
+				log.Println("invalid command:", err)
+			})
+			if err != nil && err != ctxmenu.ErrExited {
+				log.Println(err)
+			}
+		}()
+	} else if err := ctxmenu.ReadMenu(rootmenu, os.Stdin); err != nil {
+		panic(err)
 	}
 
 	res, err := rootmenu.Run(func(s string) {
 		fmt.Printf("\t%s\n", s)
 	})
-	if err != nil {
-		fmt.Printf("%s\n", res)
+	for _, t := range res.Toggles {
+		state := "off"
+		if t.Checked {
+			state = "on"
+		}
+		fmt.Printf("%s\t%s\n", state, t.Output)
+	}
+	if err == nil && *multiSelect {
+		for _, output := range res.Selected {
+			fmt.Printf("%s\n", output)
+		}
+	} else if err == nil {
+		fmt.Printf("%s\n", res.Output)
 	}
 }
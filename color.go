@@ -0,0 +1,269 @@
+package ctxmenu
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+/*
+ * Colors are written as
+ *
+ *   #RGB, #RGBA, #RRGGBB, #RRGGBBAA        hexadecimal, alpha is opaque if omitted
+ *   rgb(r, g, b), rgba(r, g, b, a)         channels 0-255 or percentages, alpha 0-1 or a percentage
+ *   hsl(h, s%, l%), hsla(h, s%, l%, a)     hue in degrees (or with unit deg, rad, turn)
+ *   steelblue                              CSS/X11 color names, case and spaces are ignored
+ *   @name                                  the theme variable name
+ *
+ * Arguments may be separated by commas or spaces, "rgb(255 0 0 / 50%)" is accepted as well.
+ */
+
+/* palette maps the names of theme variables to colors */
+type palette map[string]string
+
+func parseColor(s string) (*color.NRGBA, error) {
+	return palette(nil).parse(s)
+}
+
+/* parse s as a color, looking up variables in vars */
+func (vars palette) parse(s string) (*color.NRGBA, error) {
+	/* follow references to other variables */
+	var chain []string
+	s = strings.TrimSpace(s)
+	for strings.HasPrefix(s, "@") {
+		chain = append(chain, s)
+		if slices.Contains(chain[:len(chain)-1], s) {
+			return nil, fmt.Errorf("color variable refers to itself: %s", strings.Join(chain, " → "))
+		}
+		value, ok := vars[s[1:]]
+		if !ok {
+			return nil, fmt.Errorf("undefined color variable: %s", strings.Join(chain, " → "))
+		}
+		s = strings.TrimSpace(value)
+	}
+	c, err := parseColorValue(s)
+	if err != nil && len(chain) > 0 {
+		return nil, fmt.Errorf("%s: %w", chain[0], err)
+	}
+	return c, err
+}
+
+/* parse a color which is not a variable */
+func parseColorValue(s string) (*color.NRGBA, error) {
+	if s == "" {
+		return nil, fmt.Errorf("empty color")
+	}
+	if hex, ok := strings.CutPrefix(s, "#"); ok {
+		return parseHexColor(hex)
+	}
+	if name, args, ok := strings.Cut(s, "("); ok {
+		args, ok := strings.CutSuffix(strings.TrimSpace(args), ")")
+		if !ok {
+			return nil, fmt.Errorf("invalid color %q: missing ')'", s)
+		}
+		c, err := parseColorFunc(strings.ToLower(strings.TrimSpace(name)), args)
+		if err != nil {
+			return nil, fmt.Errorf("invalid color %q: %w", s, err)
+		}
+		return c, nil
+	}
+	key := strings.ToLower(strings.ReplaceAll(s, " ", ""))
+	if c, ok := namedColors[key]; ok {
+		return &c, nil
+	}
+	/* hex without '#' */
+	if c, err := parseHexColor(s); err == nil {
+		return c, nil
+	}
+	return nil, fmt.Errorf("unknown color: %s", s)
+}
+
+/* parse the hexadecimal forms RGB, RGBA, RRGGBB and RRGGBBAA */
+func parseHexColor(hex string) (*color.NRGBA, error) {
+	s := hex
+	switch len(s) {
+	case 3:
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2], 'f', 'f'})
+	case 4:
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2], s[3], s[3]})
+	case 6:
+		s += "ff"
+	case 8:
+		/* do nothing */
+	default:
+		return nil, fmt.Errorf("invalid color #%s: expected 3, 4, 6 or 8 hex digits", hex)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color #%s: not a hex number", hex)
+	}
+	return &color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+/* parse the arguments of rgb(), rgba(), hsl() or hsla() */
+func parseColorFunc(name, args string) (*color.NRGBA, error) {
+	fields := strings.Fields(strings.NewReplacer(",", " ", "/", " ").Replace(args))
+	if len(fields) != 3 && len(fields) != 4 {
+		return nil, fmt.Errorf("%s() takes 3 or 4 arguments, got %d", name, len(fields))
+	}
+
+	alpha := 1.0
+	if len(fields) == 4 {
+		var err error
+		alpha, err = parseFraction(fields[3], 1)
+		if err != nil {
+			return nil, fmt.Errorf("alpha: %w", err)
+		}
+	}
+
+	var r, g, b float64
+	switch name {
+	case "rgb", "rgba":
+		var channels [3]float64
+		for i, f := range fields[:3] {
+			var err error
+			channels[i], err = parseFraction(f, 255)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", [3]string{"red", "green", "blue"}[i], err)
+			}
+		}
+		r, g, b = channels[0], channels[1], channels[2]
+	case "hsl", "hsla":
+		h, err := parseHue(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("hue: %w", err)
+		}
+		s, err := parseFraction(fields[1], 100)
+		if err != nil {
+			return nil, fmt.Errorf("saturation: %w", err)
+		}
+		l, err := parseFraction(fields[2], 100)
+		if err != nil {
+			return nil, fmt.Errorf("lightness: %w", err)
+		}
+		r, g, b = hslToRGB(h, s, l)
+	default:
+		return nil, fmt.Errorf("unknown function %s(), expected rgb(), rgba(), hsl() or hsla()", name)
+	}
+	return &color.NRGBA{R: toByte(r), G: toByte(g), B: toByte(b), A: toByte(alpha)}, nil
+}
+
+/* parse a percentage or a number between 0 and max as fraction between 0 and 1 */
+func parseFraction(s string, max float64) (float64, error) {
+	if pct, ok := strings.CutSuffix(s, "%"); ok {
+		s, max = pct, 100
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("not a number: %s", s)
+	}
+	if v < 0 || v > max {
+		return 0, fmt.Errorf("%s out of range 0-%g", s, max)
+	}
+	return v / max, nil
+}
+
+/* parse a hue in degrees, or with unit deg, rad or turn, as fraction of a turn */
+func parseHue(s string) (float64, error) {
+	unit := 360.0
+	switch {
+	case strings.HasSuffix(s, "deg"):
+		s = strings.TrimSuffix(s, "deg")
+	case strings.HasSuffix(s, "rad"):
+		s, unit = strings.TrimSuffix(s, "rad"), 2*math.Pi
+	case strings.HasSuffix(s, "turn"):
+		s, unit = strings.TrimSuffix(s, "turn"), 1
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("not a number: %s", s)
+	}
+	v = math.Mod(v/unit, 1)
+	if v < 0 {
+		v++
+	}
+	return v, nil
+}
+
+/* convert hue, saturation and lightness to red, green and blue, all between 0 and 1 */
+func hslToRGB(h, s, l float64) (r, g, b float64) {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h*6, 2)-1))
+	m := l - c/2
+	switch int(h * 6) {
+	case 0:
+		r, g, b = c, x, 0
+	case 1:
+		r, g, b = x, c, 0
+	case 2:
+		r, g, b = 0, c, x
+	case 3:
+		r, g, b = 0, x, c
+	case 4:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return r + m, g + m, b + m
+}
+
+func toByte(v float64) uint8 {
+	return uint8(math.Round(min(max(v, 0), 1) * 255))
+}
+
+/* opaque returns c without transparency */
+func opaque(c *color.NRGBA) *color.NRGBA {
+	return &color.NRGBA{R: c.R, G: c.G, B: c.B, A: 0xff}
+}
+
+/* CSS color names, which are the X11 colors apart from gray, green, maroon and purple */
+var namedColors = map[string]color.NRGBA{
+	"transparent": {0, 0, 0, 0},
+}
+
+func init() {
+	for name, rgb := range map[string]uint32{
+		"aliceblue": 0xf0f8ff, "antiquewhite": 0xfaebd7, "aqua": 0x00ffff, "aquamarine": 0x7fffd4,
+		"azure": 0xf0ffff, "beige": 0xf5f5dc, "bisque": 0xffe4c4, "black": 0x000000,
+		"blanchedalmond": 0xffebcd, "blue": 0x0000ff, "blueviolet": 0x8a2be2, "brown": 0xa52a2a,
+		"burlywood": 0xdeb887, "cadetblue": 0x5f9ea0, "chartreuse": 0x7fff00, "chocolate": 0xd2691e,
+		"coral": 0xff7f50, "cornflowerblue": 0x6495ed, "cornsilk": 0xfff8dc, "crimson": 0xdc143c,
+		"cyan": 0x00ffff, "darkblue": 0x00008b, "darkcyan": 0x008b8b, "darkgoldenrod": 0xb8860b,
+		"darkgray": 0xa9a9a9, "darkgreen": 0x006400, "darkgrey": 0xa9a9a9, "darkkhaki": 0xbdb76b,
+		"darkmagenta": 0x8b008b, "darkolivegreen": 0x556b2f, "darkorange": 0xff8c00, "darkorchid": 0x9932cc,
+		"darkred": 0x8b0000, "darksalmon": 0xe9967a, "darkseagreen": 0x8fbc8f, "darkslateblue": 0x483d8b,
+		"darkslategray": 0x2f4f4f, "darkslategrey": 0x2f4f4f, "darkturquoise": 0x00ced1, "darkviolet": 0x9400d3,
+		"deeppink": 0xff1493, "deepskyblue": 0x00bfff, "dimgray": 0x696969, "dimgrey": 0x696969,
+		"dodgerblue": 0x1e90ff, "firebrick": 0xb22222, "floralwhite": 0xfffaf0, "forestgreen": 0x228b22,
+		"fuchsia": 0xff00ff, "gainsboro": 0xdcdcdc, "ghostwhite": 0xf8f8ff, "gold": 0xffd700,
+		"goldenrod": 0xdaa520, "gray": 0x808080, "green": 0x008000, "greenyellow": 0xadff2f,
+		"grey": 0x808080, "honeydew": 0xf0fff0, "hotpink": 0xff69b4, "indianred": 0xcd5c5c,
+		"indigo": 0x4b0082, "ivory": 0xfffff0, "khaki": 0xf0e68c, "lavender": 0xe6e6fa,
+		"lavenderblush": 0xfff0f5, "lawngreen": 0x7cfc00, "lemonchiffon": 0xfffacd, "lightblue": 0xadd8e6,
+		"lightcoral": 0xf08080, "lightcyan": 0xe0ffff, "lightgoldenrodyellow": 0xfafad2, "lightgray": 0xd3d3d3,
+		"lightgreen": 0x90ee90, "lightgrey": 0xd3d3d3, "lightpink": 0xffb6c1, "lightsalmon": 0xffa07a,
+		"lightseagreen": 0x20b2aa, "lightskyblue": 0x87cefa, "lightslategray": 0x778899, "lightslategrey": 0x778899,
+		"lightsteelblue": 0xb0c4de, "lightyellow": 0xffffe0, "lime": 0x00ff00, "limegreen": 0x32cd32,
+		"linen": 0xfaf0e6, "magenta": 0xff00ff, "maroon": 0x800000, "mediumaquamarine": 0x66cdaa,
+		"mediumblue": 0x0000cd, "mediumorchid": 0xba55d3, "mediumpurple": 0x9370db, "mediumseagreen": 0x3cb371,
+		"mediumslateblue": 0x7b68ee, "mediumspringgreen": 0x00fa9a, "mediumturquoise": 0x48d1cc, "mediumvioletred": 0xc71585,
+		"midnightblue": 0x191970, "mintcream": 0xf5fffa, "mistyrose": 0xffe4e1, "moccasin": 0xffe4b5,
+		"navajowhite": 0xffdead, "navy": 0x000080, "oldlace": 0xfdf5e6, "olive": 0x808000,
+		"olivedrab": 0x6b8e23, "orange": 0xffa500, "orangered": 0xff4500, "orchid": 0xda70d6,
+		"palegoldenrod": 0xeee8aa, "palegreen": 0x98fb98, "paleturquoise": 0xafeeee, "palevioletred": 0xdb7093,
+		"papayawhip": 0xffefd5, "peachpuff": 0xffdab9, "peru": 0xcd853f, "pink": 0xffc0cb,
+		"plum": 0xdda0dd, "powderblue": 0xb0e0e6, "purple": 0x800080, "rebeccapurple": 0x663399,
+		"red": 0xff0000, "rosybrown": 0xbc8f8f, "royalblue": 0x4169e1, "saddlebrown": 0x8b4513,
+		"salmon": 0xfa8072, "sandybrown": 0xf4a460, "seagreen": 0x2e8b57, "seashell": 0xfff5ee,
+		"sienna": 0xa0522d, "silver": 0xc0c0c0, "skyblue": 0x87ceeb, "slateblue": 0x6a5acd,
+		"slategray": 0x708090, "slategrey": 0x708090, "snow": 0xfffafa, "springgreen": 0x00ff7f,
+		"steelblue": 0x4682b4, "tan": 0xd2b48c, "teal": 0x008080, "thistle": 0xd8bfd8,
+		"tomato": 0xff6347, "turquoise": 0x40e0d0, "violet": 0xee82ee, "wheat": 0xf5deb3,
+		"white": 0xffffff, "whitesmoke": 0xf5f5f5, "yellow": 0xffff00, "yellowgreen": 0x9acd32,
+	} {
+		namedColors[name] = color.NRGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}
+	}
+}
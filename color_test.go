@@ -0,0 +1,104 @@
+package ctxmenu
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		text string
+		want color.NRGBA
+	}{
+		{"#abc", color.NRGBA{0xaa, 0xbb, 0xcc, 0xff}},
+		{"#1234", color.NRGBA{0x11, 0x22, 0x33, 0x44}},
+		{"#3584E4", color.NRGBA{0x35, 0x84, 0xe4, 0xff}},
+		{"#3584e480", color.NRGBA{0x35, 0x84, 0xe4, 0x80}},
+		{"3584e4", color.NRGBA{0x35, 0x84, 0xe4, 0xff}},
+		{"rgb(53, 132, 228)", color.NRGBA{53, 132, 228, 0xff}},
+		{"rgb(100%, 0%, 50%)", color.NRGBA{0xff, 0, 0x80, 0xff}},
+		{"rgba(0, 0, 0, 0.4)", color.NRGBA{0, 0, 0, 102}},
+		{"rgb(255 0 0 / 50%)", color.NRGBA{0xff, 0, 0, 0x80}},
+		{"RGBA(0 0 0 / 0)", color.NRGBA{0, 0, 0, 0}},
+		{"hsl(0, 100%, 50%)", color.NRGBA{0xff, 0, 0, 0xff}},
+		{"hsl(120, 100%, 50%)", color.NRGBA{0, 0xff, 0, 0xff}},
+		{"hsl(360, 100%, 50%)", color.NRGBA{0xff, 0, 0, 0xff}},
+		{"hsl(480, 100%, 50%)", color.NRGBA{0, 0xff, 0, 0xff}},
+		{"hsl(-120, 100%, 50%)", color.NRGBA{0, 0, 0xff, 0xff}},
+		{"hsl(0.5turn 100% 50%)", color.NRGBA{0, 0xff, 0xff, 0xff}},
+		{"hsl(240deg, 0%, 100%)", color.NRGBA{0xff, 0xff, 0xff, 0xff}},
+		{"hsla(0, 0%, 0%, 25%)", color.NRGBA{0, 0, 0, 64}},
+		{"steelblue", color.NRGBA{0x46, 0x82, 0xb4, 0xff}},
+		{"Dark Orange", color.NRGBA{0xff, 0x8c, 0x00, 0xff}},
+		{"transparent", color.NRGBA{0, 0, 0, 0}},
+	}
+	for _, test := range tests {
+		got, err := parseColor(test.text)
+		if err != nil {
+			t.Errorf("parseColor(%q): %v", test.text, err)
+			continue
+		}
+		if *got != test.want {
+			t.Errorf("parseColor(%q) = %v, want %v", test.text, *got, test.want)
+		}
+	}
+}
+
+func TestParseColorErrors(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"#12",
+		"#ggg",
+		"rgb(1, 2)",
+		"rgb(1, 2, 3, 4, 5)",
+		"rgb(256, 0, 0)",
+		"rgb(1, 2, 3",
+		"rgba(0, 0, 0, 2)",
+		"hsl(red, 50%, 50%)",
+		"hsl(0, 150%, 50%)",
+		"cmyk(0, 0, 0, 0)",
+		"notacolor",
+	} {
+		if c, err := parseColor(text); err == nil {
+			t.Errorf("parseColor(%q) = %v, expected an error", text, *c)
+		}
+	}
+}
+
+func TestPaletteParse(t *testing.T) {
+	vars := palette{
+		"accent":   "#3584e4",
+		"selected": "@accent",
+		"alias":    " @selected ",
+		"self":     "@self",
+		"ping":     "@pong",
+		"pong":     "@ping",
+		"broken":   "rgb(1, 2)",
+	}
+	tests := []struct {
+		text string
+		want string /* error substring, empty if it parses */
+	}{
+		{"@accent", ""},
+		{"@selected", ""},
+		{"@alias", ""},
+		{"@self", "@self → @self"},
+		{"@ping", "@ping → @pong → @ping"},
+		{"@missing", "undefined color variable: @missing"},
+		{"@broken", "@broken: invalid color"},
+	}
+	for _, test := range tests {
+		got, err := vars.parse(test.text)
+		switch {
+		case test.want == "" && err != nil:
+			t.Errorf("parse(%q): %v", test.text, err)
+		case test.want == "" && *got != (color.NRGBA{0x35, 0x84, 0xe4, 0xff}):
+			t.Errorf("parse(%q) = %v, want #3584e4", test.text, *got)
+		case test.want != "" && err == nil:
+			t.Errorf("parse(%q): expected an error containing %q", test.text, test.want)
+		case test.want != "" && !strings.Contains(err.Error(), test.want):
+			t.Errorf("parse(%q): error %q does not contain %q", test.text, err, test.want)
+		}
+	}
+}
@@ -13,10 +13,9 @@ import (
 	"iter"
 	"os"
 	"path"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
-	"unicode"
 
 	"github.com/KononK/resize"
 	"github.com/veandco/go-sdl2/sdl"
@@ -51,6 +50,17 @@ const (
 	AlignRight
 )
 
+type EllipsisMode int
+
+/* enum for truncating labels exceeding Config.MaxItemWidth */
+const (
+	EllipsisEnd    EllipsisMode = iota /* "A very long lab…" */
+	EllipsisMiddle                     /* "A very…ng label" */
+)
+
+/* ellipsis is inserted into truncated labels */
+const ellipsis = "…"
+
 /* ColorPair holds text-color information */
 type ColorPair struct {
 	Foreground, Background *color.NRGBA
@@ -60,19 +70,50 @@ type ColorPair struct {
 type Config struct {
 	/* the values below are set by menu.ctxmenu.h */
 	FontName           string
+	HeaderFontName     string /* font of header items, FontName in bold if unset */
 	BackgroundColor    string
 	ForegroundColor    string
 	SelbackgroundColor string
 	SelforegroundColor string
 	SeparatorColor     string
+	DisabledColor      string /* foreground of disabled items, dimmed foreground if unset */
 	BorderColor        string
+	ShadowColor        string /* color of the drop shadow, no shadow if unset */
+
+	Colors map[string]string /* theme variables, colors refer to them as "@name" */
 
 	MinItemWidth       int
+	MaxItemWidth       int          /* maximum width of an item, 0 for unlimited */
+	Ellipsis           EllipsisMode /* where to truncate labels exceeding MaxItemWidth */
 	BorderSize         int
 	SeperatorLength    int
 	IconSize           int
 	PaddingX, PaddingY int
 	Alignment          Alignment
+	AutoMnemonics      bool /* assign a unique mnemonic to items without '&'-marker, typed with alt */
+	GlobalSearch       bool /* search in the root menu finds items in all submenus */
+	KeepOpen           bool /* keep the menu open after toggling check- and radio-items */
+	MultiSelect        bool /* mark items by clicking or Space, Enter confirms the selection */
+
+	TooltipDelay time.Duration /* hover time before the tooltip of an item is shown */
+	HoverDelay   time.Duration /* hover time before the submenu of an item opens */
+	Layout       Layout        /* arrangement of menus taller than the display */
+	PipeTimeout  time.Duration /* time a pipe-command may take, unlimited if 0 */
+
+	CornerRadius int     /* radius of rounded menu corners, 0 for square corners */
+	ShadowOffset int     /* offset of the drop shadow to the bottom right */
+	ShadowBlur   int     /* blur radius of the drop shadow, 0 for a hard shadow */
+	Opacity      float64 /* opacity of menu windows, alpha of BackgroundColor if 0 */
+
+	Animation         Animation     /* transitions of menus opening and closing, 0 for none */
+	AnimationDuration time.Duration /* length of a transition */
+	Easing            Easing        /* progress of a transition over time */
+
+	Stylesheet string /* path of a stylesheet overriding colors, padding and fonts, none if empty */
+
+	ArrowStyle  ArrowStyle /* shape of submenu- and overflow-arrows */
+	ArrowScale  float64    /* size of arrows relative to the font height, 0.5 if unset */
+	ArrowGlyphs string     /* right-, up- and down-arrow for ArrowGlyph, "▸▴▾" if unset */
 }
 
 var ErrExited = errors.New("window was closed")
@@ -90,27 +131,67 @@ type Item[T comparable] struct {
 	parent     *Menu[T] /* parent */
 	output     T        /* string to be outputed when item is clicked */
 	label      string   /* string to be drawed on menu */
+	text       string   /* label as drawn, truncated to fit Config.MaxItemWidth */
+	mnemonic   rune     /* lowercase accelerator key, 0 if none */
 	labeltex   draw.Image
+	hint       string /* shortcut-hint drawn in a separate column */
+	tooltip    string /* text shown after hovering the item, empty if none */
+	hinttex    draw.Image
 	submenu    *Menu[T] /* submenu spawned by clicking on item */
 	icon       image.Image
 	overflower OverflowItem
 
-	w, h int /* item geometry */
+	disabled     bool     /* whether the item is visible but not selectable */
+	header       bool     /* whether the item is a section header */
+	separator    bool     /* whether the item is a separator showing its label as title */
+	kind         ItemKind /* normal, check- or radio-item */
+	checked      bool     /* state of check- and radio-items */
+	group        string   /* group of radio-items */
+	mark         int      /* position in the selection in multi-select mode, 0 if not marked */
+	match        []int    /* runes in label matching the search field */
+	keywords     []string /* additional terms matched by the global search */
+	classes      []string /* classes matched by the stylesheet */
+	target       *Item[T] /* item found by the global search, nil for regular items */
+	mnemonicIdx  int      /* index of the accelerator in label, -1 if none */
+	automnemonic bool     /* whether the mnemonic was assigned by assignMnemonics */
+
+	loader   func() (func() error, error) /* runs in the background when the submenu opens, returns the update to apply */
+	provider Provider[T]                  /* fills the submenu when it opens */
+	cache    bool                         /* load or provide the submenu only once */
+	loading  bool                         /* whether the loader is running */
+	loaded   bool                         /* whether the submenu was loaded or provided */
+
+	w, h  int /* item geometry, excluding the hint */
+	hintw int /* width of the hint */
 }
 
 /* Menu is a menu- or submenu-window */
 type Menu[T comparable] struct {
 	ctxmenu      *ContextMenu /* context */
 	items        []*Item[T]   /* list of items contained by the menu */
-	first        int          /* index of first element, if scrolled */
+	shown        []int        /* indices of the items matching the search field */
+	first        int          /* index in shown of first element, if scrolled */
 	selected     int          /* index of item currently selected in the menu */
-	overflow     int          /* index of first item out of sight, -1 if not overflowing */
+	overflow     int          /* number of items in sight, -1 if not overflowing */
 	x, y         int          /* menu position */
 	w, h         int          /* geometry */
 	win          *sdl.Window  /* menu window to map on the screen */
 	surf         draw.Image   /* hardware-accelerated renderer */
 	caller       *Menu[T]     /* current parent of this window, nil if root-window */
+	owner        *Item[T]     /* item spawning this menu, nil if root-window */
+	mapped       bool         /* whether the window is shown */
+	shaped       bool         /* whether the window is shaped for rounded corners and shadow */
+	decoration   decoration   /* masks of corners and shadow of a shaped window */
 	itemsChanged bool         /*  */
+	hintw        int          /* width of the widest hint */
+	indicatorw   int          /* width of the indicator-column, 0 if no item is a check- or radio-item */
+	arroww       int          /* width of the arrow-column, 0 if no item has a submenu */
+	searching    bool         /* whether the search field is shown */
+	filter       string       /* text of the search field */
+	global       bool         /* whether the menu holds results of the global search */
+	results      *Menu[T]     /* results of the global search, nil if never searched */
+	columns      []int        /* positions in shown starting a column, LayoutColumns only */
+	colw         int          /* width of a column */
 
 	overflowItemTop    *Item[T]
 	overflowItemBottom *Item[T]
@@ -123,8 +204,41 @@ type ContextMenu struct {
 	selected  ColorPair
 	border    *color.NRGBA
 	separator *color.NRGBA
+	disabled  *color.NRGBA
+	shadow    *color.NRGBA /* nil if no shadow is drawn */
+
+	font       font.Face
+	headerFont font.Face /* font of header items */
+
+	stylesheet *stylesheet /* overrides of colors, padding and fonts, nil if none */
+
+	animations []*animation /* running transitions, stepped by the event loop */
+
+	hover   hoverIntent /* delayed selection of the item under the pointer */
+	pointer image.Point /* last position of the pointer on the screen */
+
+	tooltip tooltip  /* shows tooltips and the full label of truncated items */
+	display sdl.Rect /* bounds of the display the menu is shown on */
 
-	font font.Face
+	/* arrows, rendered for the current font */
+	rightArrow  *image.Alpha
+	topArrow    *image.Alpha
+	bottomArrow *image.Alpha
+
+	/* indicators of check- and radio-items, unchecked and checked */
+	checkbox [2]*image.Alpha
+	radio    [2]*image.Alpha
+
+	selectionMark *image.Alpha /* drawn in front of marked items in multi-select mode */
+	marks         int          /* number of marks given in multi-select mode */
+
+	composition string /* text being composed by an input method */
+
+	/* functions posted from other goroutines, run by the event loop */
+	queue     []func()
+	queueLock sync.Mutex
+	wakeEvent uint32        /* event type waking up the event loop */
+	exited    chan struct{} /* closed when Run returns */
 
 	/* flags */
 	disableIcons bool /* whether to disable icons */
@@ -149,57 +263,17 @@ func parseFontString(s string) (font.Face, error) {
 	return opentype.NewFace(fnt, opts)
 }
 
-func parseColor(s string) (*color.NRGBA, error) {
-	if len(s) == 0 {
-		return nil, fmt.Errorf("empty color")
-	}
-	if s[0] == '#' {
-		s = s[1:]
-	}
-	switch len(s) {
-	case 3:
-		s = string([]byte{
-			s[0], s[0],
-			s[1], s[1],
-			s[2], s[2],
-			'f', 'f',
-		})
-	case 4:
-		s = string([]byte{
-			s[0], s[0],
-			s[1], s[1],
-			s[2], s[2],
-			s[3], s[3],
-		})
-	case 6:
-		s += "ff"
-	case 8:
-		/* do nothing */
-	default:
-		return nil, fmt.Errorf("invalid color: %s", s)
-	}
-	r, err := strconv.ParseUint(s[0:2], 16, 8)
-	if err != nil {
-		return nil, fmt.Errorf("invalid color: %s", s)
-	}
-	g, err := strconv.ParseUint(s[2:4], 16, 8)
-	if err != nil {
-		return nil, fmt.Errorf("invalid color: %s", s)
-	}
-	b, err := strconv.ParseUint(s[4:6], 16, 8)
-	if err != nil {
-		return nil, fmt.Errorf("invalid color: %s", s)
-	}
-	a, err := strconv.ParseUint(s[6:8], 16, 8)
-	if err != nil {
-		return nil, fmt.Errorf("invalid color: %s", s)
+/* mixColor blends a over b, weight is the fraction of a */
+func mixColor(a, b *color.NRGBA, weight float64) *color.NRGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x)*weight + float64(y)*(1-weight) + 0.5)
 	}
 	return &color.NRGBA{
-		R: uint8(r),
-		G: uint8(g),
-		B: uint8(b),
-		A: uint8(a),
-	}, nil
+		R: mix(a.R, b.R),
+		G: mix(a.G, b.G),
+		B: mix(a.B, b.B),
+		A: mix(a.A, b.A),
+	}
 }
 
 /* MakeMenu allocates a menu and create its window */
@@ -218,10 +292,10 @@ func MakeMenu[T comparable](ctxmenu *ContextMenu) *Menu[T] {
 	return &menu
 }
 
-func (menu *Menu[T]) Append(label string, output T, imagefile string, depth int) error {
+func (menu *Menu[T]) Append(label string, output T, imagefile string, depth int) (*Item[T], error) {
 	for range depth {
 		if len(menu.items) == 0 {
-			return fmt.Errorf("too much depth")
+			return nil, fmt.Errorf("too much depth")
 		}
 		tail := menu.items[len(menu.items)-1]
 		if tail.submenu == nil {
@@ -231,12 +305,7 @@ func (menu *Menu[T]) Append(label string, output T, imagefile string, depth int)
 		menu = tail.submenu
 	}
 
-	err := menu.AppendItem(label, output, imagefile)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return menu.AppendItem(label, output, imagefile)
 }
 
 func getDecoder(imagepath string) (func(io.Reader) (image.Image, error), error) {
@@ -254,43 +323,137 @@ func getDecoder(imagepath string) (func(io.Reader) (image.Image, error), error)
 }
 
 func (menu *Menu[T]) makeItem(label string, output T, imagefile string) (*Item[T], error) {
+	item, err := menu.newItem(label, output, imagefile)
+	if err != nil {
+		return nil, err
+	}
+	item.measure()
+	return item, nil
+}
+
+/* allocate an item and load its icon without measuring it; safe to call from any goroutine */
+func (menu *Menu[T]) newItem(label string, output T, imagefile string) (*Item[T], error) {
 	item := Item[T]{
 		parent: menu,
-		label:  label,
 		output: output,
 	}
-
-	item.w = menu.ctxmenu.PaddingX * 2
-
-	if label == "" {
-		item.h = 1 + menu.ctxmenu.PaddingY*2
-		return &item, nil
-	}
-
-	item.w += menu.ctxmenu.messureText(label)
-	item.h = menu.ctxmenu.font.Metrics().Height.Ceil() + menu.ctxmenu.PaddingY*2
+	item.label, item.mnemonic, item.mnemonicIdx = parseMnemonic(label)
 
 	/* try to load icon */
-	if imagefile != "" && !menu.ctxmenu.disableIcons {
-		dec, err := getDecoder(imagefile)
+	if label != "" {
+		var err error
+		item.icon, err = menu.ctxmenu.loadIcon(imagefile)
 		if err != nil {
 			return nil, err
 		}
+	}
+	return &item, nil
+}
 
-		r, err := os.Open(imagefile)
-		if err != nil {
-			return nil, err
-		}
-		img, err := dec(r)
-		if err != nil {
-			return nil, err
-		}
+/* loadIcon reads and scales the icon at imagefile, nil if there is none; safe to call from any goroutine */
+func (ctxmenu *ContextMenu) loadIcon(imagefile string) (image.Image, error) {
+	if imagefile == "" || ctxmenu.disableIcons {
+		return nil, nil
+	}
+	dec, err := getDecoder(imagefile)
+	if err != nil {
+		return nil, err
+	}
 
-		item.icon = resize.Resize(uint(menu.ctxmenu.IconSize), uint(menu.ctxmenu.IconSize), img, resize.Bilinear)
-		item.w += menu.ctxmenu.IconSize + menu.ctxmenu.PaddingX
-		item.h = max(item.h, menu.ctxmenu.IconSize+menu.ctxmenu.PaddingY*2)
+	r, err := os.Open(imagefile)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	img, err := dec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return resize.Resize(uint(ctxmenu.IconSize), uint(ctxmenu.IconSize), img, resize.Bilinear), nil
+}
+
+/* measure computes the geometry of an item and truncates its label to fit */
+func (item *Item[T]) measure() {
+	ctxmenu := item.parent.ctxmenu
+	pad := item.padding()
+
+	item.w = pad.X * 2
+	item.labeltex = nil
+
+	if item.label == "" {
+		item.h = 1 + pad.Y*2
+		return
+	}
+	if item.separator {
+		item.measureSeparator()
+		return
+	}
+
+	face := item.face()
+	item.h = face.Metrics().Height.Ceil() + pad.Y*2
+	if item.icon != nil {
+		item.w += ctxmenu.IconSize + pad.X
+		item.h = max(item.h, ctxmenu.IconSize+pad.Y*2)
+	}
+	if item.submenu != nil {
+		item.w += ctxmenu.rightArrow.Rect.Dx() + pad.X
+	}
+
+	item.text = item.label
+	if ctxmenu.MaxItemWidth > 0 {
+		item.text = ctxmenu.truncateText(face, item.label, ctxmenu.MaxItemWidth-item.w)
+	}
+	item.w += messureFaceText(face, item.text)
+}
+
+/* get the font the label of item is drawn in */
+func (item *Item[T]) face() font.Face {
+	if st := item.style(false); st.font != nil {
+		return st.font
+	}
+	if item.header {
+		return item.parent.ctxmenu.headerFont
+	}
+	return item.parent.ctxmenu.font
+}
+
+/* underline the mnemonic in the label-texture, if it is not truncated away */
+func (item *Item[T]) underlineMnemonic() {
+	if item.mnemonicIdx == -1 {
+		return
+	}
+	runes := []rune(item.label)
+	prefix := string(runes[:item.mnemonicIdx])
+	if !strings.HasPrefix(item.text, prefix+string(runes[item.mnemonicIdx])) {
+		return
+	}
+	face := item.face()
+	metrics := face.Metrics()
+
+	x := messureFaceText(face, prefix)
+	w := messureFaceText(face, string(runes[item.mnemonicIdx]))
+	y := min(metrics.Ascent.Ceil()+1, item.labeltex.Bounds().Dy()-1)
+	thickness := max(metrics.Height.Ceil()/16, 1)
+	draw.Draw(item.labeltex, image.Rect(x, y, x+w, y+thickness), image.Opaque, image.Point{}, draw.Src)
+}
+
+/* SetDisabled makes the item visible but not selectable */
+func (item *Item[T]) SetDisabled(disabled bool) {
+	item.disabled = disabled
+	if disabled && item.parent.selected != -1 && item.parent.items[item.parent.selected] == item {
+		item.parent.selected = -1
 	}
-	return &item, nil
+}
+
+/* selectable reports whether the item can be selected, i.e. is neither a separator, a header nor disabled */
+func (item *Item[T]) selectable() bool {
+	return !item.isSeparator() && !item.header && !item.disabled
+}
+
+/* truncated reports whether the label is not drawn completely */
+func (item *Item[T]) truncated() bool {
+	return item.text != item.label
 }
 
 func (menu *Menu[T]) makeOverflow(top bool) *Item[T] {
@@ -302,64 +465,116 @@ func (menu *Menu[T]) makeOverflow(top bool) *Item[T] {
 	if top {
 		item.overflower = OverflowTop
 	}
-	item.w = bottomArrow.Rect.Max.X + menu.ctxmenu.PaddingX*2
-	item.h = bottomArrow.Rect.Max.Y + menu.ctxmenu.PaddingY*2
+	item.w = menu.ctxmenu.bottomArrow.Rect.Dx() + menu.ctxmenu.PaddingX*2
+	item.h = menu.ctxmenu.bottomArrow.Rect.Dy() + menu.ctxmenu.PaddingY*2
 	return &item
 }
 
-func (menu *Menu[T]) AppendItem(label string, output T, imagefile string) error {
+func (menu *Menu[T]) AppendItem(label string, output T, imagefile string) (*Item[T], error) {
 	item, err := menu.makeItem(label, output, imagefile)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	menu.items = append(menu.items, item)
 	menu.itemsChanged = true
-	return nil
+	return item, nil
+}
+
+/* SetHint sets the shortcut-hint drawn right-aligned in the item, e.g. "Ctrl+S" */
+func (item *Item[T]) SetHint(hint string) {
+	item.hint = hint
+	item.hinttex = nil
+	item.hintw = 0
+	if hint != "" {
+		item.hintw = item.parent.ctxmenu.messureText(hint)
+	}
+	item.parent.itemsChanged = true
+}
+
+/* SetTooltip sets the text shown after hovering the item for Config.TooltipDelay */
+func (item *Item[T]) SetTooltip(text string) {
+	item.tooltip = text
 }
 
 func (item *Item[T]) setSubmenu(sub *Menu[T]) {
-	item.w += rightArrow.Rect.Max.X
-	item.parent.w = max(item.parent.w, item.w)
 	item.submenu = sub
+	sub.owner = item
+	item.measure()
+	item.parent.w = max(item.parent.w, item.w)
 }
 
 func (ctxmenu *ContextMenu) drawText(dest draw.Image, text string) int {
+	return drawFaceText(ctxmenu.font, dest, text)
+}
+
+func (ctxmenu *ContextMenu) messureText(text string) int {
+	return messureFaceText(ctxmenu.font, text)
+}
+
+/* draw text in face into the mask dest */
+func drawFaceText(face font.Face, dest draw.Image, text string) int {
 	var dot fixed.Point26_6
 	dot.X = 0
-	dot.Y = ctxmenu.font.Metrics().Ascent
+	dot.Y = face.Metrics().Ascent
 
 	prev := rune(-1)
 	for _, chr := range text {
 		if prev != -1 {
-			dot.X += ctxmenu.font.Kern(prev, chr)
+			dot.X += face.Kern(prev, chr)
 		}
 		prev = chr
-		dr, mask, maskp, advance, _ := ctxmenu.font.Glyph(dot, chr)
+		dr, mask, maskp, advance, _ := face.Glyph(dot, chr)
 		draw.DrawMask(dest, dr, image.Opaque, image.Point{}, mask, maskp, draw.Src)
 		dot.X += advance
 	}
 	return dot.X.Ceil()
 }
 
-func (ctxmenu *ContextMenu) messureText(text string) int {
+func messureFaceText(face font.Face, text string) int {
 	prev := rune(-1)
 	width := fixed.Int26_6(0)
 	for _, chr := range text {
 		if prev != -1 {
-			width += ctxmenu.font.Kern(prev, chr)
+			width += face.Kern(prev, chr)
 		}
 		prev = chr
-		advance, _ := ctxmenu.font.GlyphAdvance(chr)
+		advance, _ := face.GlyphAdvance(chr)
 		width += advance
 	}
 	return width.Ceil()
 }
 
+/* truncateText shortens text in face with an ellipsis so it fits in width pixels */
+func (ctxmenu *ContextMenu) truncateText(face font.Face, text string, width int) string {
+	if messureFaceText(face, text) <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	head, tail := len(runes), 0
+	if ctxmenu.Ellipsis == EllipsisMiddle {
+		head = (len(runes) + 1) / 2
+		tail = len(runes) / 2
+	}
+	for head+tail > 0 {
+		/* remove alternately from the head and the tail */
+		if tail > 0 && tail >= head {
+			tail--
+		} else {
+			head--
+		}
+		short := string(runes[:head]) + ellipsis + string(runes[len(runes)-tail:])
+		if messureFaceText(face, short) <= width {
+			return short
+		}
+	}
+	return ellipsis
+}
+
 func (menu *Menu[T]) updateWindow() error {
 	var err error
 	if menu.win == nil {
-		menu.win, err = sdl.CreateWindow("menu", int32(menu.x), int32(menu.y), int32(menu.w), int32(menu.h), sdl.WINDOW_SHOWN|sdl.WINDOW_POPUP_MENU)
-		if err != nil {
+		if err := menu.createWindow(); err != nil {
 			return err
 		}
 		menu.surf, err = menu.win.GetSurface()
@@ -367,9 +582,14 @@ func (menu *Menu[T]) updateWindow() error {
 			return err
 		}
 	} else {
-		menu.win.SetSize(int32(menu.w), int32(menu.h))
+		menu.win.SetSize(int32(menu.w+menu.margin()), int32(menu.h+menu.margin()))
 		menu.win.SetPosition(int32(menu.x), int32(menu.y))
-		menu.win.Show()
+
+		/* the surface is invalidated by resizing */
+		menu.surf, err = menu.win.GetSurface()
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -384,7 +604,27 @@ func (menu *Menu[T]) show(caller *Menu[T]) error {
 	if caller != nil {
 		caller.hideChildren(menu)
 	}
+	if !menu.mapped && menu.owner != nil {
+		/* generate the items of the submenu when it opens */
+		menu.owner.provide()
+		menu.owner.load()
+	}
+	opening := !menu.mapped
+	menu.mapped = true
 
+	if err := menu.layout(caller); err != nil {
+		return err
+	}
+	if opening {
+		menu.animateOpen()
+	} else {
+		menu.animateRelayout()
+	}
+	return nil
+}
+
+/* compute the geometry and position of menu and update its window, open submenus are kept */
+func (menu *Menu[T]) layout(caller *Menu[T]) error {
 	display, err := menu.win.GetDisplayIndex()
 	if err != nil {
 		sdl.PumpEvents()
@@ -411,28 +651,59 @@ func (menu *Menu[T]) show(caller *Menu[T]) error {
 	if err != nil {
 		return err
 	}
+	menu.ctxmenu.display = mr
 
 	if menu.itemsChanged {
 		menu.itemsChanged = false
+		menu.assignMnemonics()
+		menu.applyFilter()
 		menu.w = menu.ctxmenu.BorderSize*2 + menu.ctxmenu.MinItemWidth
-		menu.h = menu.ctxmenu.BorderSize * 2
+		menu.h = menu.ctxmenu.BorderSize*2 + menu.fieldHeight()
 		menu.first = 0
 		menu.overflow = -1
+		menu.columns = nil
+
+		/* hints and arrows are aligned in columns across the menu */
+		menu.hintw = 0
+		menu.arroww = 0
+		menu.indicatorw = 0
+		if menu.ctxmenu.MultiSelect {
+			menu.indicatorw = menu.ctxmenu.checkbox[0].Rect.Dx() + menu.ctxmenu.PaddingX
+		}
+		for _, item := range menu.items {
+			menu.hintw = max(menu.hintw, item.hintw)
+			if item.submenu != nil {
+				menu.arroww = menu.ctxmenu.rightArrow.Rect.Dx() + menu.ctxmenu.PaddingX
+			}
+			if item.kind != KindNormal {
+				menu.indicatorw = menu.ctxmenu.checkbox[0].Rect.Dx() + menu.ctxmenu.PaddingX
+			}
+		}
 
+		/* the width does not depend on the search field to keep the menu steady */
 		for _, item := range menu.items {
-			menu.w = max(menu.w, item.w)
-			menu.h += item.h
+			menu.w = max(menu.w, menu.itemWidth(item))
+		}
+		for _, i := range menu.shown {
+			menu.h += menu.items[i].h
 		}
 
-		if menu.h > int(mr.Y+mr.H) {
+		if menu.h > int(mr.Y+mr.H) && menu.ctxmenu.Layout == LayoutColumns {
+			/* wrap into columns of the full width */
+			bw := menu.ctxmenu.BorderSize
+			colh := menu.wrapColumns(int(mr.H) - bw*2 - menu.fieldHeight())
+			menu.colw = menu.w - bw*2
+			menu.w = bw*2 + menu.colw*len(menu.columns)
+			menu.h = bw*2 + menu.fieldHeight() + colh
+		} else if menu.h > int(mr.Y+mr.H) {
 			/* both arrow items */
-			menu.h = (bottomArrow.Rect.Max.Y + menu.ctxmenu.PaddingY*2 + menu.ctxmenu.BorderSize) * 2
-			for i, item := range menu.items {
+			menu.h = (menu.ctxmenu.bottomArrow.Rect.Dy()+menu.ctxmenu.PaddingY*2+menu.ctxmenu.BorderSize)*2 + menu.fieldHeight()
+			for n, i := range menu.shown {
+				item := menu.items[i]
 				if item.h+menu.h > int(mr.Y+mr.H) {
-					menu.overflow = i
+					menu.overflow = n
 					break
 				}
-				menu.w = max(menu.w, item.w)
 				menu.h += item.h
 			}
 		}
@@ -440,21 +711,25 @@ func (menu *Menu[T]) show(caller *Menu[T]) error {
 
 	if caller != nil && menu.caller != caller {
 		menu.caller = caller
-		menu.x = caller.x + caller.w
+		bw := menu.ctxmenu.BorderSize
+
+		/* open next to the column of the selected item */
+		left, right := caller.x, caller.x+caller.w
+		rect, ok := caller.itemRect(caller.selected)
+		if ok {
+			left, right = caller.x+rect.Min.X-bw, caller.x+rect.Max.X+bw
+		}
+		menu.x = right
 
 		if menu.x < int(mr.X) {
 			menu.x = int(mr.X)
 		} else if menu.x+menu.w > int(mr.X+mr.W) {
-			menu.x = caller.x - menu.w
+			menu.x = left - menu.w
 		}
 		if menu.overflow == -1 {
 			menu.y = caller.y
-			start := 0
-			if caller.overflow != -1 {
-				start = caller.first
-			}
-			for i := start; i < caller.selected; i++ {
-				menu.y += caller.items[i].h
+			if ok {
+				menu.y += rect.Min.Y - bw
 			}
 		}
 	} else if menu.x == -1 || menu.y == -1 {
@@ -477,8 +752,19 @@ func (menu *Menu[T]) show(caller *Menu[T]) error {
 		menu.y = int(mr.Y+mr.H) - menu.h
 	}
 
-	menu.updateWindow()
-	return nil
+	return menu.updateWindow()
+}
+
+/* get the width an item requires inside menu, including the hint- and arrow-columns */
+func (menu *Menu[T]) itemWidth(item *Item[T]) int {
+	w := item.w
+	if item.submenu != nil {
+		w -= menu.arroww
+	}
+	if menu.hintw > 0 {
+		w += menu.hintw + menu.ctxmenu.PaddingX*2
+	}
+	return w + menu.indicatorw + menu.arroww
 }
 
 func (menu *Menu[T]) hideChildren(except *Menu[T]) {
@@ -491,64 +777,94 @@ func (menu *Menu[T]) hideChildren(except *Menu[T]) {
 
 func (menu *Menu[T]) hide() {
 	menu.hideChildren(nil)
-	menu.win.Hide()
+	if menu.searching {
+		menu.setFilter(false, "")
+	}
+	if !menu.mapped {
+		return
+	}
+	menu.mapped = false
+	menu.animateClose()
 }
 
 /* draw overflow button */
-func (menu *Menu[T]) drawItem(y int, index int, item *Item[T]) error {
+func (menu *Menu[T]) drawItem(rect image.Rectangle, index int, item *Item[T]) error {
 	// x := menu.ctxmenu.vertpadding
 	// y += menu.ctxmenu.horzpadding
+	w := rect.Dx()
+	pad := item.padding()
+	color := item.colors(index != -1 && index == menu.selected)
 
-	color := menu.ctxmenu.normal
-	if index != -1 && index == menu.selected {
-		color = menu.ctxmenu.selected
-	}
-
-	img := &SubImage{menu.surf, image.Rect(0, y, menu.w, y+item.h)}
+	img := &SubImage{menu.surf, rect}
 
-	draw.Draw(img, img.Bounds(), image.NewUniform(color.Background), image.Point{}, draw.Src)
+	/* the background is opaque, its alpha is the opacity of the window */
+	draw.Draw(img, img.Bounds(), image.NewUniform(opaque(menu.ctxmenu.normal.Background)), image.Point{}, draw.Src)
+	if color.Background != menu.ctxmenu.normal.Background {
+		draw.Draw(img, img.Bounds(), image.NewUniform(color.Background), image.Point{}, draw.Over)
+	}
 
 	if item.overflower != OverflowNone {
-		pixels := topArrow
+		pixels := menu.ctxmenu.topArrow
 		if item.overflower == OverflowBottom {
-			pixels = bottomArrow
+			pixels = menu.ctxmenu.bottomArrow
 		}
 
-		x := menu.w/2 - bottomArrow.Rect.Max.X/2
-		y := item.h/2 - bottomArrow.Rect.Max.Y/2
+		x := w/2 - pixels.Rect.Dx()/2
+		y := item.h/2 - pixels.Rect.Dy()/2
 
 		draw.DrawMask(img, pixels.Bounds().Add(image.Point{x, y}), image.NewUniform(color.Foreground), image.Point{}, pixels, image.Point{}, draw.Over)
+	} else if item.separator && item.label != "" {
+		item.drawSeparator(img)
 	} else if item.label != "" {
-		x := menu.ctxmenu.PaddingX + menu.ctxmenu.BorderSize
+		x := pad.X + menu.indicatorw
 		if item.icon != nil {
-			x += menu.ctxmenu.IconSize + menu.ctxmenu.PaddingX
+			x += menu.ctxmenu.IconSize + pad.X
 		}
 
-		textH := menu.ctxmenu.font.Metrics().Height.Ceil()
-		textW := menu.ctxmenu.messureText(item.label)
+		textH := item.face().Metrics().Height.Ceil()
+		textW := messureFaceText(item.face(), item.text)
 		if item.labeltex == nil {
 			item.labeltex = image.NewAlpha(image.Rect(0, 0, textW, textH))
-			menu.ctxmenu.drawText(item.labeltex, item.label)
+			drawFaceText(item.face(), item.labeltex, item.text)
+			item.underlineMnemonic()
 		}
 		textY := item.h/2 - textH/2
 
+		item.drawMatch(img, x, textY, color)
 		draw.DrawMask(img, item.labeltex.Bounds().Add(image.Point{x, textY}), image.NewUniform(color.Foreground), image.Point{}, item.labeltex, image.Point{}, draw.Over)
 
+		if item.hint != "" {
+			if item.hinttex == nil {
+				item.hinttex = image.NewAlpha(image.Rect(0, 0, item.hintw, textH))
+				menu.ctxmenu.drawText(item.hinttex, item.hint)
+			}
+			x := w - pad.X - menu.arroww - item.hintw
+			dim := mixColor(color.Foreground, color.Background, 0.6)
+			draw.DrawMask(img, item.hinttex.Bounds().Add(image.Point{x, textY}), image.NewUniform(dim), image.Point{}, item.hinttex, image.Point{}, draw.Over)
+		}
+
 		if item.submenu != nil {
-			x := menu.w - rightArrow.Rect.Max.X - menu.ctxmenu.BorderSize - menu.ctxmenu.PaddingX
-			y := item.h/2 - rightArrow.Rect.Max.Y/2
-			draw.DrawMask(img, rightArrow.Bounds().Add(image.Point{x, y}), image.NewUniform(color.Foreground), image.Point{}, rightArrow, image.Point{}, draw.Over)
+			arrow := menu.ctxmenu.rightArrow
+			x := w - arrow.Rect.Dx() - pad.X
+			y := item.h/2 - arrow.Rect.Dy()/2
+			draw.DrawMask(img, arrow.Bounds().Add(image.Point{x, y}), image.NewUniform(color.Foreground), image.Point{}, arrow, image.Point{}, draw.Over)
+		}
+
+		if mask := item.indicator(); mask != nil {
+			x := pad.X
+			y := item.h/2 - mask.Rect.Dy()/2
+			draw.DrawMask(img, mask.Bounds().Add(image.Point{x, y}), image.NewUniform(color.Foreground), image.Point{}, mask, image.Point{}, draw.Over)
 		}
 
 		if item.icon != nil {
-			x := menu.ctxmenu.BorderSize + menu.ctxmenu.PaddingX
+			x := pad.X + menu.indicatorw
 			y := item.h/2 - menu.ctxmenu.IconSize/2
 			draw.Draw(img, image.Rect(x, y, x+menu.ctxmenu.IconSize, y+menu.ctxmenu.IconSize), item.icon, image.Point{}, draw.Over)
 		}
 	} else {
-		x := menu.ctxmenu.BorderSize + menu.ctxmenu.PaddingX + menu.ctxmenu.SeperatorLength
-		y := menu.ctxmenu.PaddingY
-		draw.Draw(img, image.Rect(x, y, x+menu.w-x*2, y+1), image.NewUniform(menu.ctxmenu.separator), image.Point{}, draw.Src)
+		x := pad.X + menu.ctxmenu.SeperatorLength
+		y := pad.Y
+		draw.Draw(img, image.Rect(x, y, w-x, y+1), image.NewUniform(color.Foreground), image.Point{}, draw.Over)
 	}
 	return nil
 }
@@ -561,12 +877,12 @@ func (menu *Menu[T]) visibleItems(withOverflow bool) iter.Seq2[int, *Item[T]] {
 			}
 		}
 		start := 0
-		end := len(menu.items)
+		end := len(menu.shown)
 		if menu.overflow != -1 {
 			start = menu.first
-			end = menu.first + menu.overflow
+			end = min(menu.first+menu.overflow, end)
 		}
-		for i := start; i < end; i++ {
+		for _, i := range menu.shown[start:end] {
 			if !yield(i, menu.items[i]) {
 				return
 			}
@@ -581,25 +897,17 @@ func (menu *Menu[T]) visibleItems(withOverflow bool) iter.Seq2[int, *Item[T]] {
 
 /* draw pixmap for the selected and unselected version of each item on menu */
 func (menu *Menu[T]) draw() error {
-	y := menu.ctxmenu.BorderSize
-
-	for i, item := range menu.visibleItems(true) {
-		menu.drawItem(y, i, item)
-		y += item.h
-	}
+	menu.drawField()
 
+	/* clear the space below short columns */
 	bw := menu.ctxmenu.BorderSize
-	/* top */
-	draw.Draw(menu.surf, image.Rect(0, 0, menu.w, bw), image.NewUniform(menu.ctxmenu.border), image.Point{}, draw.Src)
+	draw.Draw(menu.surf, image.Rect(bw, bw+menu.fieldHeight(), menu.w-bw, menu.h-bw), image.NewUniform(opaque(menu.ctxmenu.normal.Background)), image.Point{}, draw.Src)
 
-	/* bottom */
-	draw.Draw(menu.surf, image.Rect(0, menu.h-bw, menu.w, menu.h), image.NewUniform(menu.ctxmenu.border), image.Point{}, draw.Src)
-
-	/* left */
-	draw.Draw(menu.surf, image.Rect(0, 0, bw, menu.h), image.NewUniform(menu.ctxmenu.border), image.Point{}, draw.Src)
+	for p := range menu.placeItems(true) {
+		menu.drawItem(p.rect, p.index, p.item)
+	}
 
-	/* right */
-	draw.Draw(menu.surf, image.Rect(menu.w-bw, 0, menu.w, menu.h), image.NewUniform(menu.ctxmenu.border), image.Point{}, draw.Src)
+	menu.drawBorder()
 
 	menu.win.UpdateSurface()
 	return nil
@@ -610,12 +918,15 @@ func (menu *Menu[T]) getmenu(win uint32) *Menu[T] {
 	if menu == nil {
 		return nil
 	}
-	if menu.win != nil {
+	if menu.win != nil && menu.mapped {
 		id, err := menu.win.GetID()
 		if err == nil && id == win {
 			return menu
 		}
 	}
+	if w := menu.results.getmenu(win); w != nil {
+		return w
+	}
 	for _, item := range menu.items {
 		w := item.submenu.getmenu(win)
 		if w != nil {
@@ -625,15 +936,13 @@ func (menu *Menu[T]) getmenu(win uint32) *Menu[T] {
 	return nil
 }
 
-/* get in *ret the item in given menu and position; return 1 if position is on a scroll triangle */
-func (menu *Menu[T]) getitem(target int) int {
-	y := menu.ctxmenu.BorderSize
-
-	for i, item := range menu.visibleItems(true) {
-		if i != -1 && y <= target && target < y+item.h {
-			return i
+/* get the item in given menu at position x, y; -1 if none */
+func (menu *Menu[T]) getitem(x, y int) int {
+	target := image.Point{x, y}
+	for p := range menu.placeItems(true) {
+		if p.index != -1 && target.In(p.rect) {
+			return p.index
 		}
-		y += item.h
 	}
 
 	return -1
@@ -643,7 +952,7 @@ func (menu *Menu[T]) isoverflowitem(target int) OverflowItem {
 	if menu == nil || menu.overflow == -1 {
 		return OverflowNone
 	}
-	y := menu.ctxmenu.BorderSize
+	y := menu.ctxmenu.BorderSize + menu.fieldHeight()
 
 	item := menu.overflowItemTop
 	if y <= target && target < y+item.h {
@@ -663,146 +972,196 @@ func (menu *Menu[T]) isoverflowitem(target int) OverflowItem {
 	return OverflowNone
 }
 
-/* cycle through the items; non-zero direction is next, zero is prev */
+/* cycle through the shown items in given direction, skipping separators and disabled items */
 func (menu *Menu[T]) itemcycle(direction int) int {
-	/* menu.selected item (either separator or labeled item) in given direction */
-	item := -1
+	n := len(menu.shown)
+	if n == 0 {
+		return -1
+	}
+
+	/* position of the selected item in shown */
+	pos := -1
+	for p, i := range menu.shown {
+		if i == menu.selected {
+			pos = p
+			break
+		}
+	}
+
+	/* starting position and step in given direction */
+	p, step := 0, 1
 	switch direction {
 	case ItemNext:
-		if menu.selected == -1 {
-			item = 0
-		} else if menu.selected < len(menu.items)-1 {
-			item = menu.selected + 1
-		}
+		p = pos + 1
 	case ItemPrev:
-		if menu.selected == -1 {
-			item = len(menu.items) - 1
-		} else if menu.selected >= 0 {
-			item = menu.selected - 1
+		p, step = pos-1, -1
+		if pos == -1 {
+			p = n - 1
 		}
 	case ItemFirst:
-		item = 0
+		p = 0
 	case ItemLast:
-		item = len(menu.items) - 1
+		p, step = n-1, -1
 	}
 
-	/*
-	 * the selected item can be a separator
-	 * let's menu.selected the closest labeled item (ie., one that isn't a separator)
-	 */
-	switch direction {
-	case ItemNext:
-	case ItemFirst:
-		for ; item < len(menu.items) && menu.items[item].label == ""; item++ {
-		}
-		if menu.items[item].label == "" {
-			item = 0
-		}
-	case ItemPrev:
-	case ItemLast:
-		for ; item >= 0 && menu.items[item].label == ""; item-- {
-		}
-		if menu.items[item].label == "" {
-			item = len(menu.items) - 1
+	/* select the closest selectable item, wrapping around */
+	for range n {
+		p = (p%n + n) % n
+		if menu.items[menu.shown[p]].selectable() {
+			return menu.shown[p]
 		}
+		p += step
 	}
-	return item
+	return -1
 }
 
-/* get item in menu matching text from given direction (or from beginning, if dir = 0) */
-func (menu *Menu[T]) matchitem(text string, dir int) int {
-	// struct Item *item, *lastitem;
-	dirinc := 0
-	switch {
-	case dir < 0:
-		dirinc = -1
-	case dir >= 0:
-		dirinc = 1
+func (menu *Menu[T]) warp() bool {
+	if menu.selected == -1 {
+		return false
 	}
-
-	item := -1
-	if dir < 0 {
-		if menu.selected != -1 && menu.selected > 0 {
-			item = menu.selected - 1
-		} else {
-			item = len(menu.items) - 1
-		}
-	} else if dir > 0 {
-		if menu.selected != -1 && menu.selected < len(menu.items)-1 {
-			item = menu.selected + 1
-		} else {
-			item = 0
-		}
-	} else {
-		item = 0
+	rect, ok := menu.itemRect(menu.selected)
+	if !ok {
+		return false
 	}
-	/* find next item from selected item */
+	y := menu.y + (rect.Min.Y+rect.Max.Y)/2
+	x := menu.x + (rect.Min.X+rect.Max.X)/2
+	sdl.WarpMouseGlobal(int32(x), int32(y))
+	return true
+}
 
-	for ; item >= 0 && item < len(menu.items); item += dirinc {
-		for s := menu.items[item].label; len(s) > 0; s = s[1:] {
-			if s == text {
-				return item
-			}
-		}
+/* show the tooltip of the selected item after the delay, or the full label of a truncated item at once */
+func (menu *Menu[T]) updateTooltip() {
+	if menu.selected == -1 {
+		menu.ctxmenu.hideTooltip()
+		return
 	}
-	/* if not found, try to find from the beginning/end of list */
-	if dir > 0 {
-		item = 0
-	} else {
-		item = len(menu.items) - 1
+	item := menu.items[menu.selected]
+	rect, ok := menu.itemRect(menu.selected)
+	if !ok {
+		menu.ctxmenu.hideTooltip()
+		return
 	}
-	for ; item >= 0 && item < len(menu.items); item += dirinc {
-		for s := menu.items[item].label; len(s) > 0; s = s[1:] {
-			if s == text {
-				return item
-			}
-		}
+	anchor := rect.Add(image.Point{menu.x, menu.y})
+	text := item.tooltip
+	if item.target != nil {
+		text = item.target.tooltip
+	}
+	switch {
+	case text != "":
+		menu.ctxmenu.scheduleTooltip(text, anchor)
+	case item.truncated():
+		menu.ctxmenu.showTooltip(item.label, anchor)
+	default:
+		menu.ctxmenu.hideTooltip()
 	}
-	return -1
 }
 
-func (menu *Menu[T]) warp() bool {
-	y := menu.ctxmenu.BorderSize
-	for i, item := range menu.visibleItems(true) {
-		if i != -1 && i == menu.selected {
-			y += menu.y + item.h/2
-			x := menu.x + menu.w/2
-			sdl.WarpMouseGlobal(int32(x), int32(y))
-			return true
-		}
-		y += item.h
+/* Run shows the menu and returns the activated item and the state of all check- and radio-items */
+func (rootmenu *Menu[T]) Run(hover func(T)) (Result[T], error) {
+	output, err := rootmenu.run(hover)
+	res := Result[T]{
+		Output:  output,
+		Toggles: rootmenu.toggles(nil),
 	}
-	return false
+	if rootmenu.ctxmenu.MultiSelect {
+		res.Selected = rootmenu.selection()
+	}
+	return res, err
 }
 
 /* run event loop */
-func (rootmenu *Menu[T]) Run(hover func(T)) (def T, err error) {
+func (rootmenu *Menu[T]) run(hover func(T)) (def T, err error) {
+	rootmenu.ctxmenu.startLoop()
+	defer rootmenu.ctxmenu.stopLoop()
 	if err := rootmenu.show(nil); err != nil {
 		return def, err
 	}
 
 	curmenu := rootmenu
-	var buf []byte
 	var previtem *Item[T]
+	var hovermenu *Menu[T] /* menu of the pending hover action */
+	var aimitem *Item[T]   /* item selected by the pending hover action while aiming at a submenu */
 	// curmenu.selected := -1
 	var hasleft *time.Timer
 	warped := false
+	skipText := false /* the key was handled, drop the text it produces */
 	action := Action(0)
 	quit := make(chan struct{})
+
+	/* select the item under the pointer, opening its submenu */
+	hoverItem := func(menu *Menu[T], itemidx int) {
+		item := menu.items[itemidx]
+		previtem = item
+		if item.selectable() {
+			menu.selected = itemidx
+		} else {
+			menu.selected = -1
+		}
+		menu.draw()
+		menu.updateTooltip()
+		if item.submenu != nil && item.selectable() {
+			open := func() {
+				curmenu = item.submenu
+				curmenu.selected = -1
+				curmenu.show(menu)
+			}
+			if rootmenu.ctxmenu.HoverDelay > 0 && !item.submenu.mapped {
+				curmenu = menu
+				menu.hideChildren(nil)
+				hovermenu = menu
+				rootmenu.ctxmenu.deferHover(rootmenu.ctxmenu.HoverDelay, func() {
+					if menu.mapped && menu.selected == itemidx {
+						open()
+						curmenu.draw()
+					}
+				})
+			} else {
+				open()
+			}
+		} else {
+			curmenu = menu
+			menu.hideChildren(nil)
+		}
+		if item.selectable() && hover != nil {
+			hover(item.output)
+		}
+	}
+
+	/* typed text arrives as text input events, composed by the input method if any */
+	sdl.StartTextInput()
+	defer sdl.StopTextInput()
+	defer rootmenu.close()
 	for {
 		select {
 		case <-quit:
 			return def, ErrExited
 		default:
 		}
-		event := sdl.WaitEventTimeout(100)
+		rootmenu.ctxmenu.pollTooltip()
+		rootmenu.ctxmenu.stepAnimations()
+		rootmenu.ctxmenu.pollHover()
+		event := sdl.WaitEventTimeout(rootmenu.ctxmenu.hoverTimeout(rootmenu.ctxmenu.animationTimeout(rootmenu.ctxmenu.tooltipTimeout(100))))
 		if event == nil {
 			continue
 		}
 		action = 0
+		switch event.(type) {
+		case *sdl.KeyboardEvent, *sdl.TextInputEvent, *sdl.MouseButtonEvent:
+			/* the keyboard or a click take over from the pointer */
+			rootmenu.ctxmenu.cancelHover()
+		}
 		switch ev := event.(type) {
 		case *sdl.QuitEvent:
 			return def, ErrExited
+		case *sdl.UserEvent:
+			if ev.Type != rootmenu.ctxmenu.wakeEvent {
+				break
+			}
+			rootmenu.ctxmenu.flush()
+			/* the current menu may have been removed */
+			for !curmenu.mapped && curmenu.caller != nil {
+				curmenu = curmenu.caller
+			}
 		case *sdl.WindowEvent:
 			if ev.Event == sdl.WINDOWEVENT_LEAVE && rootmenu.ctxmenu.seen {
 				hasleft = time.AfterFunc(100*time.Millisecond, func() {
@@ -821,6 +1180,10 @@ func (rootmenu *Menu[T]) Run(hover func(T)) (def T, err error) {
 				warped = false
 				break
 			}
+			if rootmenu.ctxmenu.isTooltip(ev.WindowID) {
+				rootmenu.ctxmenu.hideTooltip()
+				continue
+			}
 			menu := rootmenu.getmenu(ev.WindowID)
 			if rootmenu.ctxmenu.seen && menu == nil {
 				return def, ErrExited
@@ -828,55 +1191,66 @@ func (rootmenu *Menu[T]) Run(hover func(T)) (def T, err error) {
 			if menu == nil {
 				continue
 			}
-			itemidx := menu.getitem(int(ev.Y))
+			if menu != hovermenu {
+				rootmenu.ctxmenu.cancelHover()
+				hovermenu, aimitem = nil, nil
+			}
+			from := rootmenu.ctxmenu.pointer
+			pointer := image.Point{menu.x + int(ev.X), menu.y + int(ev.Y)}
+			rootmenu.ctxmenu.pointer = pointer
+			itemidx := menu.getitem(int(ev.X), int(ev.Y))
 			if itemidx == -1 {
 				continue
 			}
 			item := menu.items[itemidx]
 			if previtem == item {
+				if aimitem != nil {
+					/* back on the item of the open submenu */
+					rootmenu.ctxmenu.cancelHover()
+					aimitem = nil
+				}
 				continue
 			}
 			rootmenu.ctxmenu.seen = true
-			previtem = item
-			if item.label == "" {
-				menu.selected = -1
-			} else {
-				menu.selected = itemidx
-			}
-			menu.draw()
-			if item.submenu != nil {
-				curmenu = item.submenu
-				curmenu.selected = -1
-			} else {
-				curmenu = menu
-			}
-			curmenu.show(menu)
-			if item.label != "" && hover != nil {
-				hover(item.output)
+			if menu.aiming(item, from, pointer) {
+				/* heading into the open submenu, select item once the pointer rests */
+				hovermenu, aimitem = menu, item
+				rootmenu.ctxmenu.deferHover(aimTimeout, func() {
+					aimitem = nil
+					if menu.mapped && itemidx < len(menu.items) && menu.items[itemidx] == item {
+						hoverItem(menu, itemidx)
+						curmenu.draw()
+					}
+				})
+				continue
 			}
-			action = ActionClear | ActionMap | ActionDraw
+			rootmenu.ctxmenu.cancelHover()
+			aimitem = nil
+			hoverItem(menu, itemidx)
+			action = ActionMap | ActionDraw
 		case *sdl.MouseWheelEvent:
 			if curmenu.overflow == -1 {
 				break
 			}
 			if ev.Y < 0 {
 				curmenu.first = max(curmenu.first-1, 0)
-				action = ActionClear | ActionMap | ActionDraw
+				action = ActionDraw
 				break
 			} else if ev.Y > 0 {
-				curmenu.first = min(curmenu.first+1, len(curmenu.items)-curmenu.overflow)
-				action = ActionClear | ActionMap | ActionDraw
+				curmenu.first = min(curmenu.first+1, len(curmenu.shown)-curmenu.overflow)
+				action = ActionDraw
 				break
 			}
 		case *sdl.MouseButtonEvent:
 			if ev.State != sdl.PRESSED {
 				break
 			}
+			rootmenu.ctxmenu.hideTooltip()
 			menu := curmenu.getmenu(ev.WindowID)
 			if menu == nil {
 				return def, ErrExited
 			}
-			item := menu.getitem(int(ev.Y))
+			item := menu.getitem(int(ev.X), int(ev.Y))
 			ovitem := menu.isoverflowitem(int(ev.Y))
 			if item == -1 && ovitem == OverflowNone {
 				curmenu.selected = -1
@@ -886,23 +1260,26 @@ func (rootmenu *Menu[T]) Run(hover func(T)) (def T, err error) {
 			}
 			if ovitem == OverflowTop {
 				curmenu.first = max(curmenu.first-1, 0)
-				action = ActionClear | ActionMap | ActionDraw
+				action = ActionDraw
 				break
 			} else if ovitem == OverflowBottom {
-				curmenu.first = min(curmenu.first+1, len(curmenu.items)-curmenu.overflow)
-				action = ActionClear | ActionMap | ActionDraw
+				curmenu.first = min(curmenu.first+1, len(curmenu.shown)-curmenu.overflow)
+				action = ActionDraw
 				break
 			}
-			if menu.items[item].label == "" {
-				return /* ignore separators */
+			if !menu.items[item].selectable() {
+				break /* ignore separators and disabled items */
 			}
-			if menu.items[item].submenu != nil {
-				curmenu = menu.items[item].submenu
-				curmenu.show(menu)
-			} else {
-				return menu.items[item].output, nil
+			if menu.items[item].submenu == nil {
+				if menu.activate(item) {
+					return menu.items[item].output, nil
+				}
+				menu.draw()
+				break
 			}
-			curmenu.selected = 0
+			curmenu = menu.items[item].submenu
+			curmenu.show(menu)
+			curmenu.selected = curmenu.itemcycle(ItemFirst)
 			action = ActionClear | ActionMap | ActionDraw
 			if ev.Button == sdl.BUTTON_MIDDLE {
 				action |= ActionWarp
@@ -911,6 +1288,17 @@ func (rootmenu *Menu[T]) Run(hover func(T)) (def T, err error) {
 			if ev.State != sdl.PRESSED {
 				break
 			}
+			rootmenu.ctxmenu.hideTooltip()
+			skipText = false
+			if rootmenu.ctxmenu.composition != "" {
+				break /* keys belong to the input method while composing */
+			}
+
+			/* esc closes the search field first */
+			if ev.Keysym.Sym == sdl.K_ESCAPE && curmenu.searching {
+				action = ActionClear | ActionDraw
+				break
+			}
 
 			/* esc closes ctxmenu when current menu is the root menu */
 			if ev.Keysym.Sym == sdl.K_ESCAPE && curmenu.caller == nil {
@@ -918,84 +1306,160 @@ func (rootmenu *Menu[T]) Run(hover func(T)) (def T, err error) {
 			}
 
 			/* cycle through menu */
-			curmenu.selected = -1
 			switch ev.Keysym.Sym {
 			case sdl.K_HOME:
 				curmenu.selected = curmenu.itemcycle(ItemFirst)
-				action = ActionClear | ActionDraw
+				action = ActionDraw
 			case sdl.K_END:
 				curmenu.selected = curmenu.itemcycle(ItemLast)
-				action = ActionClear | ActionDraw
+				action = ActionDraw
 			case sdl.K_TAB:
 				if ev.Keysym.Mod&sdl.KMOD_SHIFT > 0 {
-					if len(buf) > 0 {
-						curmenu.selected = curmenu.matchitem(string(buf), -1)
-						action = ActionDraw
-					} else {
-						curmenu.selected = curmenu.itemcycle(ItemPrev)
-						action = ActionClear | ActionDraw
-					}
+					curmenu.selected = curmenu.itemcycle(ItemPrev)
 				} else {
-					if len(buf) > 0 {
-						curmenu.selected = curmenu.matchitem(string(buf), 1)
-						action = ActionDraw
-					} else {
-						curmenu.selected = curmenu.itemcycle(ItemNext)
-						action = ActionClear | ActionDraw
-					}
+					curmenu.selected = curmenu.itemcycle(ItemNext)
 				}
+				action = ActionDraw
 			case sdl.K_UP:
 				curmenu.selected = curmenu.itemcycle(ItemPrev)
-				action = ActionClear | ActionDraw
+				action = ActionDraw
 			case sdl.K_DOWN:
 				curmenu.selected = curmenu.itemcycle(ItemNext)
-				action = ActionClear | ActionDraw
+				action = ActionDraw
 			case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+				if curmenu.searching {
+					break /* digits are typed into the search field */
+				}
+				skipText = true
 				item := curmenu.itemcycle(ItemFirst)
 				for range ev.Keysym.Sym - '0' {
 					curmenu.selected = item
 					item = curmenu.itemcycle(ItemNext)
 				}
 				curmenu.selected = item
-				action = ActionClear | ActionDraw
+				action = ActionDraw
 			case sdl.K_RETURN, sdl.K_RIGHT:
+				if item := curmenu.columnitem(1); item != -1 && ev.Keysym.Sym == sdl.K_RIGHT {
+					/* move to the next column first */
+					curmenu.selected = item
+					action = ActionDraw
+					break
+				}
+				if ev.Keysym.Sym == sdl.K_RETURN && curmenu.ctxmenu.MultiSelect &&
+					(curmenu.selected == -1 || curmenu.items[curmenu.selected].submenu == nil) {
+					/* confirm the selection, or the current item if nothing is marked */
+					if len(rootmenu.marked(nil)) == 0 && curmenu.selected != -1 && curmenu.items[curmenu.selected].selectable() {
+						curmenu.items[curmenu.selected].toggleMark()
+					}
+					return def, nil
+				}
 				if curmenu.selected != -1 {
-					if curmenu.items[curmenu.selected].label == "" {
-						return /* ignore separators */
+					if !curmenu.items[curmenu.selected].selectable() {
+						break /* ignore separators and disabled items */
 					}
-					if curmenu.items[curmenu.selected].submenu != nil {
-						curmenu = curmenu.items[curmenu.selected].submenu
-						curmenu.show(curmenu)
-					} else {
-						return curmenu.items[curmenu.selected].output, nil
+					if curmenu.items[curmenu.selected].submenu == nil {
+						if curmenu.activate(curmenu.selected) {
+							return curmenu.items[curmenu.selected].output, nil
+						}
+						action = ActionDraw
+						break
 					}
-					curmenu.selected = 0
+					sub := curmenu.items[curmenu.selected].submenu
+					sub.show(curmenu)
+					curmenu = sub
+					curmenu.selected = curmenu.itemcycle(ItemFirst)
 					action = ActionClear | ActionMap | ActionDraw
 				}
 			case sdl.K_ESCAPE, sdl.K_LEFT:
+				if item := curmenu.columnitem(-1); item != -1 && ev.Keysym.Sym == sdl.K_LEFT {
+					/* move to the previous column first */
+					curmenu.selected = item
+					action = ActionDraw
+					break
+				}
 				if curmenu.caller != nil {
 					curmenu.selected = curmenu.caller.selected
 					curmenu = curmenu.caller
 					action = ActionClear | ActionMap | ActionDraw
 				}
-			case sdl.K_BACKSPACE, sdl.K_CLEAR, sdl.K_DELETE:
+			case sdl.K_BACKSPACE:
+				/* remove the last character, close the empty search field */
+				if filter := []rune(curmenu.filter); len(filter) > 0 && curmenu.global {
+					curmenu, _ = rootmenu.showResults(string(filter[:len(filter)-1]))
+					action = ActionDraw
+				} else if len(filter) > 0 {
+					curmenu.setFilter(true, string(filter[:len(filter)-1]))
+					curmenu.show(curmenu.caller)
+					action = ActionDraw
+				} else if curmenu.searching {
+					action = ActionClear | ActionDraw
+				}
+			case sdl.K_CLEAR, sdl.K_DELETE:
 				action = ActionClear | ActionDraw
-			default:
-				if !unicode.IsPrint(rune(ev.Keysym.Sym)) {
+			case sdl.K_SPACE:
+				if !curmenu.ctxmenu.MultiSelect || curmenu.searching {
 					break
 				}
-				for range 2 {
-					buf = append(buf, byte(ev.Keysym.Sym))
-					if curmenu.selected = curmenu.matchitem(string(buf), 0); curmenu.selected != -1 {
-						break
-					}
-					buf = buf[:0]
+				/* space marks the current item */
+				skipText = true
+				if curmenu.selected != -1 && curmenu.items[curmenu.selected].selectable() &&
+					curmenu.items[curmenu.selected].submenu == nil {
+					curmenu.activate(curmenu.selected)
 				}
 				action = ActionDraw
 			}
+		case *sdl.TextEditingEvent:
+			/* show the text being composed in the search field */
+			rootmenu.ctxmenu.composition = ev.GetText()
+			if rootmenu.ctxmenu.composition != "" && !curmenu.searching {
+				curmenu.setFilter(true, curmenu.filter)
+				curmenu.show(curmenu.caller)
+			}
+			action = ActionDraw
+		case *sdl.TextInputEvent:
+			rootmenu.ctxmenu.composition = ""
+			text := ev.GetText()
+			if skipText || text == "" {
+				skipText = false
+				break
+			}
+			mod := sdl.GetModState()
+			if idx := curmenu.mnemonicitem(text, mod&sdl.KMOD_ALT != 0); idx != -1 && !curmenu.searching && mod&sdl.KMOD_CTRL == 0 {
+				/* mnemonics activate the item directly */
+				curmenu.selected = idx
+				item := curmenu.items[idx]
+				if item.submenu == nil {
+					if curmenu.activate(idx) {
+						return item.output, nil
+					}
+					action = ActionDraw
+					break
+				}
+				curmenu.draw()
+				item.submenu.show(curmenu)
+				curmenu = item.submenu
+				curmenu.selected = curmenu.itemcycle(ItemFirst)
+				action = ActionClear | ActionMap | ActionDraw
+				break
+			}
+			if text == "/" && !curmenu.searching {
+				/* open an empty search field */
+				text = ""
+			}
+			if curmenu.global || curmenu == rootmenu && rootmenu.ctxmenu.GlobalSearch {
+				curmenu, _ = rootmenu.showResults(curmenu.filter + text)
+			} else {
+				curmenu.setFilter(true, curmenu.filter+text)
+				curmenu.show(curmenu.caller)
+			}
+			action = ActionDraw
 		}
-		if action&ActionClear != 0 {
-			buf = buf[:0]
+		if action&ActionClear != 0 && curmenu.global {
+			rootmenu.hideResults()
+			curmenu = rootmenu
+		} else if action&ActionClear != 0 && curmenu.searching {
+			curmenu.setFilter(false, "")
+			curmenu.show(curmenu.caller)
 		}
 		if action&ActionDraw != 0 {
 			err := curmenu.draw()
@@ -1015,33 +1479,85 @@ func XmenuInit(conf Config) (*ContextMenu, error) {
 	/* initializers */
 	var err error
 	ctxmenu.Config = conf
-	ctxmenu.normal.Background, err = parseColor(ctxmenu.BackgroundColor)
+	vars := palette(conf.Colors)
+	ctxmenu.normal.Background, err = vars.parse(ctxmenu.BackgroundColor)
 	if err != nil {
 		return nil, err
 	}
-	ctxmenu.normal.Foreground, err = parseColor(ctxmenu.ForegroundColor)
+	ctxmenu.normal.Foreground, err = vars.parse(ctxmenu.ForegroundColor)
 	if err != nil {
 		return nil, err
 	}
-	ctxmenu.selected.Background, err = parseColor(ctxmenu.SelbackgroundColor)
+	ctxmenu.selected.Background, err = vars.parse(ctxmenu.SelbackgroundColor)
 	if err != nil {
 		return nil, err
 	}
-	ctxmenu.selected.Foreground, err = parseColor(ctxmenu.SelforegroundColor)
+	ctxmenu.selected.Foreground, err = vars.parse(ctxmenu.SelforegroundColor)
 	if err != nil {
 		return nil, err
 	}
-	ctxmenu.separator, err = parseColor(ctxmenu.SeparatorColor)
+	ctxmenu.separator, err = vars.parse(ctxmenu.SeparatorColor)
 	if err != nil {
 		return nil, err
 	}
-	ctxmenu.border, err = parseColor(ctxmenu.BorderColor)
+	ctxmenu.border, err = vars.parse(ctxmenu.BorderColor)
 	if err != nil {
 		return nil, err
 	}
+	if ctxmenu.DisabledColor != "" {
+		ctxmenu.disabled, err = vars.parse(ctxmenu.DisabledColor)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ctxmenu.disabled = mixColor(ctxmenu.normal.Foreground, ctxmenu.normal.Background, 0.5)
+	}
+	if ctxmenu.ShadowColor != "" {
+		ctxmenu.shadow, err = vars.parse(ctxmenu.ShadowColor)
+		if err != nil {
+			return nil, err
+		}
+	}
 	ctxmenu.font, err = parseFontString(ctxmenu.Config.FontName)
 	if err != nil {
 		return nil, err
 	}
+	if ctxmenu.Stylesheet != "" {
+		ctxmenu.stylesheet, err = loadStylesheet(ctxmenu.Stylesheet, vars)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if ctxmenu.Animation != 0 && PrefersReducedMotion() {
+		/* the desktop asks for no animations */
+		ctxmenu.Animation = 0
+	}
+	headerFont := ctxmenu.HeaderFontName
+	if headerFont == "" {
+		headerFont = ctxmenu.FontName + ":bold"
+	}
+	ctxmenu.headerFont, err = parseFontString(headerFont)
+	if err != nil {
+		return nil, err
+	}
+	ctxmenu.rightArrow, err = ctxmenu.makeArrow(arrowRight)
+	if err != nil {
+		return nil, err
+	}
+	ctxmenu.topArrow, err = ctxmenu.makeArrow(arrowUp)
+	if err != nil {
+		return nil, err
+	}
+	ctxmenu.bottomArrow, err = ctxmenu.makeArrow(arrowDown)
+	if err != nil {
+		return nil, err
+	}
+	for i, checked := range []bool{false, true} {
+		ctxmenu.checkbox[i] = ctxmenu.makeIndicator(KindCheck, checked)
+		ctxmenu.radio[i] = ctxmenu.makeIndicator(KindRadio, checked)
+	}
+	ctxmenu.selectionMark = ctxmenu.makeIndicator(KindNormal, true)
+	ctxmenu.wakeEvent = sdl.RegisterEvents(1)
+	ctxmenu.exited = make(chan struct{})
 	return &ctxmenu, err
 }
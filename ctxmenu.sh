@@ -1,6 +1,6 @@
 #!/bin/sh
 
-cmd/ctxmenu/ctxmenu -l <<EOF
+cmd/ctxmenu/ctxmenu <<EOF
 Terminal
 Settings
 Applications
@@ -0,0 +1,30 @@
+package ctxmenu
+
+import (
+	"testing"
+
+	"golang.org/x/image/font/basicfont"
+)
+
+func TestTruncateText(t *testing.T) {
+	face := basicfont.Face7x13 /* every glyph is 7 pixels wide */
+	tests := []struct {
+		mode  EllipsisMode
+		text  string
+		width int
+		want  string
+	}{
+		{EllipsisEnd, "abcdefghij", 70, "abcdefghij"},
+		{EllipsisEnd, "abcdefghij", 35, "abcd…"},
+		{EllipsisEnd, "abcdefghij", 40, "abcd…"},
+		{EllipsisMiddle, "abcdefghij", 35, "ab…ij"},
+		{EllipsisMiddle, "abcdefghij", 42, "abc…ij"},
+		{EllipsisEnd, "abcdefghij", 3, "…"},
+	}
+	for _, test := range tests {
+		ctxmenu := &ContextMenu{Config: Config{Ellipsis: test.mode}}
+		if got := ctxmenu.truncateText(face, test.text, test.width); got != test.want {
+			t.Errorf("truncateText(%q, %d) in mode %d = %q, want %q", test.text, test.width, test.mode, got, test.want)
+		}
+	}
+}
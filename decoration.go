@@ -0,0 +1,217 @@
+package ctxmenu
+
+import (
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/* ordered 4x4 dither matrix, used to fake a translucent shadow with a binary window shape */
+var bayer4 = [4][4]uint8{
+	{8, 136, 40, 168},
+	{200, 72, 232, 104},
+	{56, 184, 24, 152},
+	{248, 120, 216, 88},
+}
+
+/* decorated reports whether menus need a shaped window for rounded corners or a shadow */
+func (ctxmenu *ContextMenu) decorated() bool {
+	return ctxmenu.CornerRadius > 0 || ctxmenu.shadow != nil
+}
+
+/* get the opacity of menu windows, Config.Opacity or the alpha of the background */
+func (ctxmenu *ContextMenu) opacity() float32 {
+	if ctxmenu.Opacity > 0 {
+		return float32(min(ctxmenu.Opacity, 1))
+	}
+	return float32(ctxmenu.normal.Background.A) / 255
+}
+
+/* get the space right and below the menu taken by the shadow, 0 if the window is not shaped */
+func (menu *Menu[T]) margin() int {
+	if !menu.shaped || menu.ctxmenu.shadow == nil {
+		return 0
+	}
+	return menu.ctxmenu.ShadowOffset + menu.ctxmenu.ShadowBlur
+}
+
+/* create the menu window, shaped if decorated and supported by the backend */
+func (menu *Menu[T]) createWindow() error {
+	var err error
+	/* shown by show, after the first step of the transition */
+	flags := uint32(sdl.WINDOW_HIDDEN | sdl.WINDOW_POPUP_MENU)
+	if menu.ctxmenu.decorated() {
+		menu.shaped = true
+		w, h := menu.w+menu.margin(), menu.h+menu.margin()
+		menu.win, err = sdl.CreateShapedWindow("menu", 0, 0, uint32(w), uint32(h), flags)
+		if err == nil && menu.win != nil {
+			menu.win.SetPosition(int32(menu.x), int32(menu.y))
+		} else {
+			/* no shaped windows, draw square corners without shadow */
+			menu.shaped = false
+			menu.win = nil
+		}
+	}
+	if menu.win == nil {
+		menu.win, err = sdl.CreateWindow("menu", int32(menu.x), int32(menu.y), int32(menu.w), int32(menu.h), flags)
+		if err != nil {
+			return err
+		}
+	}
+	if opacity := menu.ctxmenu.opacity(); opacity < 1 {
+		/* fails without compositor, the menu stays opaque */
+		menu.win.SetWindowOpacity(opacity)
+	}
+	return nil
+}
+
+/* masks of a decorated menu, computed once per size */
+type decoration struct {
+	size   image.Point
+	ring   *image.Alpha /* border between the outer and the inner outline, nil if not rounded */
+	shadow *image.Alpha /* pixels outside the menu showing the shadow */
+}
+
+/* draw the border, rounding the corners and casting the shadow if the window is shaped */
+func (menu *Menu[T]) drawBorder() {
+	bw := menu.ctxmenu.BorderSize
+	border := image.NewUniform(menu.borderColor())
+	if !menu.shaped || menu.ctxmenu.CornerRadius <= 0 {
+		/* top, bottom, left and right */
+		draw.Draw(menu.surf, image.Rect(0, 0, menu.w, bw), border, image.Point{}, draw.Over)
+		draw.Draw(menu.surf, image.Rect(0, menu.h-bw, menu.w, menu.h), border, image.Point{}, draw.Over)
+		draw.Draw(menu.surf, image.Rect(0, bw, bw, menu.h-bw), border, image.Point{}, draw.Over)
+		draw.Draw(menu.surf, image.Rect(menu.w-bw, bw, menu.w, menu.h-bw), border, image.Point{}, draw.Over)
+	}
+	if !menu.shaped {
+		return
+	}
+
+	if menu.decoration.size != (image.Point{menu.w, menu.h}) {
+		menu.decorate()
+	}
+	deco := &menu.decoration
+	if deco.ring != nil {
+		draw.DrawMask(menu.surf, deco.ring.Rect, border, image.Point{}, deco.ring, image.Point{}, draw.Over)
+	}
+	if deco.shadow != nil {
+		draw.DrawMask(menu.surf, deco.shadow.Rect, image.NewUniform(opaque(menu.ctxmenu.shadow)), image.Point{}, deco.shadow, image.Point{}, draw.Src)
+	}
+}
+
+/* compute the masks of the decoration and shape the window */
+func (menu *Menu[T]) decorate() {
+	deco := &menu.decoration
+	deco.size = image.Point{menu.w, menu.h}
+	deco.ring = nil
+	deco.shadow = nil
+
+	bw := menu.ctxmenu.BorderSize
+	r := menu.ctxmenu.CornerRadius
+	outer := rasterize(menu.w, menu.h, roundedOutline(menu.w, menu.h, r, 0))
+	if r > 0 {
+		inner := rasterize(menu.w, menu.h, roundedOutline(menu.w, menu.h, max(r-bw, 0), bw))
+		deco.ring = image.NewAlpha(outer.Rect)
+		for i := range deco.ring.Pix {
+			deco.ring.Pix[i] = uint8(int(outer.Pix[i]) * (255 - int(inner.Pix[i])) / 255)
+		}
+	}
+
+	/* pixels with half coverage or more belong to the menu */
+	m := menu.margin()
+	shape := image.NewAlpha(image.Rect(0, 0, menu.w+m, menu.h+m))
+	for i, a := range outer.Pix {
+		if a >= 128 {
+			shape.Pix[shape.PixOffset(i%menu.w, i/menu.w)] = 255
+		}
+	}
+
+	/* the blurred shadow is dithered into the shape */
+	if shadow := menu.ctxmenu.shadow; shadow != nil {
+		off := menu.ctxmenu.ShadowOffset
+		cast := image.NewAlpha(shape.Rect)
+		draw.Draw(cast, outer.Rect.Add(image.Point{off, off}), outer, image.Point{}, draw.Src)
+		cast = blurAlpha(blurAlpha(cast, menu.ctxmenu.ShadowBlur/2), menu.ctxmenu.ShadowBlur/2)
+
+		deco.shadow = image.NewAlpha(shape.Rect)
+		for y := range shape.Rect.Dy() {
+			for x := range shape.Rect.Dx() {
+				i := shape.PixOffset(x, y)
+				a := int(cast.Pix[i]) * int(shadow.A) / 255
+				if shape.Pix[i] == 0 && a > int(bayer4[y%4][x%4]) {
+					shape.Pix[i] = 255
+					deco.shadow.Pix[i] = 255
+				}
+			}
+		}
+	}
+	menu.setShape(shape)
+}
+
+/* apply shape as the window shape, transparent pixels are cut out */
+func (menu *Menu[T]) setShape(shape *image.Alpha) {
+	surf, err := sdl.CreateRGBSurfaceWithFormat(0, int32(shape.Rect.Dx()), int32(shape.Rect.Dy()), 32, sdl.PIXELFORMAT_RGBA32)
+	if err != nil || surf == nil {
+		return
+	}
+	defer surf.Free()
+	draw.Draw(surf, shape.Rect, shape, image.Point{}, draw.Src)
+	menu.win.SetShape(surf, sdl.ShapeModeBinarizeAlpha{Cutoff: 128})
+}
+
+/* outline of a rectangle of w*h pixels with rounded corners of radius r, inset by d pixels, in unit-coordinates */
+func roundedOutline(w, h, r, d int) [][2]float32 {
+	const segments = 8
+	x0, y0 := float64(d), float64(d)
+	x1, y1 := float64(w-d), float64(h-d)
+	rad := min(float64(r), (x1-x0)/2, (y1-y0)/2)
+
+	/* centers of the corner-arcs, clockwise from the top-right */
+	centers := [4][2]float64{
+		{x1 - rad, y0 + rad},
+		{x1 - rad, y1 - rad},
+		{x0 + rad, y1 - rad},
+		{x0 + rad, y0 + rad},
+	}
+	var outline [][2]float32
+	for c, center := range centers {
+		for i := range segments + 1 {
+			angle := math.Pi/2*float64(c) - math.Pi/2 + math.Pi/2*float64(i)/segments
+			outline = append(outline, [2]float32{
+				float32((center[0] + rad*math.Cos(angle)) / float64(w)),
+				float32((center[1] + rad*math.Sin(angle)) / float64(h)),
+			})
+		}
+	}
+	return outline
+}
+
+/* blur a mask with a box of 2*r+1 pixels, horizontally and vertically */
+func blurAlpha(src *image.Alpha, r int) *image.Alpha {
+	if r <= 0 {
+		return src
+	}
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	pass := func(src *image.Alpha, n, m int, at func(i, j int) int) *image.Alpha {
+		dst := image.NewAlpha(src.Rect)
+		for j := range m {
+			sum := 0
+			for i := -r; i < n+r; i++ {
+				if i+r < n && i+r >= 0 {
+					sum += int(src.Pix[at(i+r, j)])
+				}
+				if i-r-1 >= 0 && i-r-1 < n {
+					sum -= int(src.Pix[at(i-r-1, j)])
+				}
+				if i >= 0 && i < n {
+					dst.Pix[at(i, j)] = uint8(sum / (2*r + 1))
+				}
+			}
+		}
+		return dst
+	}
+	horz := pass(src, w, h, func(i, j int) int { return src.PixOffset(i, j) })
+	return pass(horz, h, w, func(i, j int) int { return src.PixOffset(j, i) })
+}
@@ -0,0 +1,170 @@
+package ctxmenu
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+/* entry is a parsed line of the text format */
+type entry struct {
+	depth                        int
+	label, output, imgpath, hint string
+	tooltip                      string
+	disabled, header, separator  bool
+	kind                         ItemKind
+	group                        string
+	checked                      bool
+	keywords                     []string
+	classes                      []string /* classes matched by the stylesheet */
+	id                           string   /* name to address the item in a stream */
+	pipe                         string   /* command generating the submenu */
+	cache                        bool     /* run pipe only once */
+}
+
+/* parseEntry parses a tab-indented line: positional fields and tags,
+ * a field starting with '\' is positional with the backslash removed, so "\HEADER" is the label "HEADER" */
+func parseEntry(line string, delim rune) (entry, error) {
+	var e entry
+	text := []rune(line)
+	for len(text) > 0 && text[0] == delim {
+		e.depth++
+		text = text[1:]
+	}
+
+	var fields []string
+	for f := range strings.SplitSeq(string(text), string(delim)) {
+		switch {
+		case f == "":
+			/* do nothing */
+		case strings.HasPrefix(f, `\`):
+			fields = append(fields, f[1:])
+		case f == "DISABLED":
+			e.disabled = true
+		case f == "HEADER":
+			e.header = true
+		case strings.HasPrefix(f, "SEPARATOR:"):
+			e.separator = true
+			e.label = strings.TrimPrefix(f, "SEPARATOR:")
+		case f == "CHECK", f == "CHECK:on":
+			e.kind = KindCheck
+			e.checked = f == "CHECK:on"
+		case strings.HasPrefix(f, "TIP:"):
+			e.tooltip = strings.TrimPrefix(f, "TIP:")
+		case strings.HasPrefix(f, "PIPE:"):
+			e.pipe = strings.TrimPrefix(f, "PIPE:")
+		case strings.HasPrefix(f, "ID:"):
+			e.id = strings.TrimPrefix(f, "ID:")
+		case f == "CACHE":
+			e.cache = true
+		case strings.HasPrefix(f, "KEYWORDS:"):
+			e.keywords = strings.Split(strings.TrimPrefix(f, "KEYWORDS:"), ",")
+		case strings.HasPrefix(f, "CLASS:"):
+			e.classes = strings.Split(strings.TrimPrefix(f, "CLASS:"), ",")
+		case strings.HasPrefix(f, "RADIO:"):
+			e.kind = KindRadio
+			e.group = strings.TrimPrefix(f, "RADIO:")
+			e.group, e.checked = strings.CutSuffix(e.group, ":on")
+		default:
+			fields = append(fields, f)
+		}
+	}
+	if e.separator {
+		if len(fields) > 0 {
+			return e, fmt.Errorf("separator with fields: %s", string(text))
+		}
+		return e, nil
+	}
+	switch len(fields) {
+	case 0:
+		/* do nothing */
+	case 1:
+		e.label = fields[0]
+		e.output, _, _ = parseMnemonic(fields[0]) /* "&File" prints "File" */
+	case 2:
+		e.label = fields[0]
+		e.output = fields[1]
+	case 3:
+		e.imgpath = strings.TrimPrefix(fields[0], "IMG:")
+		e.label = fields[1]
+		e.output = fields[2]
+	case 4:
+		e.imgpath = strings.TrimPrefix(fields[0], "IMG:")
+		e.label = fields[1]
+		e.output = fields[2]
+		e.hint = fields[3]
+	default:
+		return e, fmt.Errorf("too many fields: %s", string(text))
+	}
+	return e, nil
+}
+
+/* apply sets the attributes of e on an appended item */
+func (e *entry) apply(item *Item[string]) {
+	item.SetHint(e.hint)
+	item.SetTooltip(e.tooltip)
+	item.SetDisabled(e.disabled)
+	item.SetHeader(e.header)
+	item.SetSeparator(e.separator)
+	item.SetKeywords(e.keywords...)
+	if e.classes != nil {
+		item.SetClass(e.classes...)
+	}
+	switch e.kind {
+	case KindCheck:
+		item.SetCheckbox(e.checked)
+	case KindRadio:
+		item.SetRadio(e.group, e.checked)
+	}
+	if e.pipe != "" {
+		SetPipe(item, e.pipe, e.cache)
+	}
+}
+
+/* append the item described by e to menu */
+func (e *entry) append(menu *Menu[string]) (*Item[string], error) {
+	item, err := menu.Append(e.label, e.output, e.imgpath, e.depth)
+	if err != nil {
+		return nil, err
+	}
+	e.apply(item)
+	return item, nil
+}
+
+/* parse all lines read from r */
+func parseEntries(r io.Reader) ([]entry, error) {
+	var entries []entry
+	scan := bufio.NewScanner(r)
+	for scan.Scan() {
+		e, err := parseEntry(scan.Text(), '\t')
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, scan.Err()
+}
+
+/* AppendLine appends the item described by a tab-indented line of the text format to menu */
+func AppendLine(menu *Menu[string], line string) (*Item[string], error) {
+	e, err := parseEntry(line, '\t')
+	if err != nil {
+		return nil, err
+	}
+	return e.append(menu)
+}
+
+/* ReadMenu appends an item to menu for every line read from r */
+func ReadMenu(menu *Menu[string], r io.Reader) error {
+	entries, err := parseEntries(r)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if _, err := e.append(menu); err != nil {
+			return err
+		}
+	}
+	return nil
+}
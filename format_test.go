@@ -0,0 +1,46 @@
+package ctxmenu
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEntry(t *testing.T) {
+	tests := []struct {
+		line string
+		want entry
+	}{
+		{"label", entry{label: "label", output: "label"}},
+		{"&File", entry{label: "&File", output: "File"}},
+		{"R&D\tr&d", entry{label: "R&D", output: "r&d"}},
+		{"\t\tlabel\toutput", entry{depth: 2, label: "label", output: "output"}},
+		{"IMG:icon.png\tlabel\toutput\tCtrl+S", entry{imgpath: "icon.png", label: "label", output: "output", hint: "Ctrl+S"}},
+		{"label\tDISABLED\tHEADER", entry{label: "label", output: "label", disabled: true, header: true}},
+		{"label\tCHECK:on\tID:x\tCLASS:a,b", entry{label: "label", output: "label", kind: KindCheck, checked: true, id: "x", classes: []string{"a", "b"}}},
+		{"label\tRADIO:size:on", entry{label: "label", output: "label", kind: KindRadio, group: "size", checked: true}},
+		{"SEPARATOR:Recent", entry{label: "Recent", separator: true}},
+		{`\HEADER` + "\t" + `\ID:3`, entry{label: "HEADER", output: "ID:3"}},
+		{`\\x`, entry{label: `\x`, output: `\x`}},
+	}
+	for _, test := range tests {
+		got, err := parseEntry(test.line, '\t')
+		if err != nil {
+			t.Errorf("parseEntry(%q): %v", test.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseEntry(%q) = %+v, want %+v", test.line, got, test.want)
+		}
+	}
+}
+
+func TestParseEntryErrors(t *testing.T) {
+	for _, line := range []string{
+		"SEPARATOR:title\tlabel",
+		"a\tb\tc\td\te",
+	} {
+		if _, err := parseEntry(line, '\t'); err == nil {
+			t.Errorf("parseEntry(%q): expected an error", line)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package ctxmenu
+
+import (
+	"math"
+	"slices"
+	"unicode"
+)
+
+/* separator of the breadcrumb path shown in global search results */
+const breadcrumbSeparator = " › "
+
+/* SetKeywords sets additional terms the global search matches the item by */
+func (item *Item[T]) SetKeywords(keywords ...string) {
+	item.keywords = keywords
+}
+
+/* a leaf found by the global search */
+type searchHit[T comparable] struct {
+	item  *Item[T]
+	path  string /* breadcrumb path including the label */
+	score int
+	match []int /* matched runes in path */
+}
+
+/* the highest penalty of a gap between two matched runes, so matching word starts far apart wins over close letters */
+const maxGap = 3
+
+/* fuzzy matches the runes of query in order inside text ignoring case and accents,
+ * returns a score, the matched runes and whether it matched at all; the score may be negative.
+ * Of all alignments, the one preferring word starts and consecutive runes is chosen */
+func fuzzy(text, query string) (score int, match []int, ok bool) {
+	hay, origin := fold(text)
+	needle, _ := fold(query)
+	if len(needle) == 0 {
+		return 0, nil, true
+	}
+
+	const none = math.MinInt / 2
+	/* best[j][i] is the highest score of needle[:j+1] with needle[j] at hay[i], from[j][i] where needle[j-1] is */
+	best := make([][]int, len(needle))
+	from := make([][]int, len(needle))
+	for j, chr := range needle {
+		best[j] = make([]int, len(hay))
+		from[j] = make([]int, len(hay))
+		farBest, farFrom := none, -1 /* highest best[j-1][p] for p < i-3, a gap of maxGap or more */
+		for i := range hay {
+			if j > 0 && i >= maxGap+1 && best[j-1][i-maxGap-1] > farBest {
+				farBest, farFrom = best[j-1][i-maxGap-1], i-maxGap-1
+			}
+			best[j][i] = none
+			if hay[i] != chr {
+				continue
+			}
+			bonus := 1
+			if i == 0 || !unicode.IsLetter(hay[i-1]) && !unicode.IsDigit(hay[i-1]) {
+				bonus += 3 /* start of a word */
+			}
+			if j == 0 {
+				best[j][i], from[j][i] = bonus, -1
+				continue
+			}
+			if i >= 1 && best[j-1][i-1] != none {
+				best[j][i], from[j][i] = best[j-1][i-1]+5+bonus, i-1 /* consecutive characters */
+			}
+			/* penalize the gap between the matched characters, up to maxGap */
+			for p := max(i-maxGap, 0); p < i-1; p++ {
+				if best[j-1][p] != none && best[j-1][p]-(i-p-1)+bonus > best[j][i] {
+					best[j][i], from[j][i] = best[j-1][p]-(i-p-1)+bonus, p
+				}
+			}
+			if farBest != none && farBest-maxGap+bonus > best[j][i] {
+				best[j][i], from[j][i] = farBest-maxGap+bonus, farFrom
+			}
+		}
+	}
+
+	last := -1
+	for i, n := range best[len(needle)-1] {
+		if n != none && (last == -1 || n > best[len(needle)-1][last]) {
+			last = i
+		}
+	}
+	if last == -1 {
+		return 0, nil, false
+	}
+	score = best[len(needle)-1][last]
+
+	/* walk back and map onto the runes of text */
+	found := make([]int, len(needle))
+	for j, i := len(needle)-1, last; j >= 0; j-- {
+		found[j] = i
+		i = from[j][i]
+	}
+	for _, i := range found {
+		if len(match) == 0 || match[len(match)-1] != origin[i] {
+			match = append(match, origin[i])
+		}
+	}
+	return score, match, true
+}
+
+/* collect all leaves of menu and its submenus matching query;
+ * submenus filled by a pipe or provider hold their items of the last time they were opened, none before */
+func (menu *Menu[T]) searchLeaves(query string, prefix string, hits []searchHit[T]) []searchHit[T] {
+	for _, item := range menu.items {
+		if !item.selectable() {
+			continue
+		}
+		if item.submenu != nil {
+			hits = item.submenu.searchLeaves(query, prefix+item.label+breadcrumbSeparator, hits)
+			continue
+		}
+
+		offset := len([]rune(prefix))
+		score, match, ok := fuzzy(item.label, query)
+		for i := range match {
+			match[i] += offset
+		}
+		for _, keyword := range item.keywords {
+			if kwscore, _, kwok := fuzzy(keyword, query); kwok && (!ok || kwscore > score) {
+				score, match, ok = kwscore, nil, true
+			}
+		}
+		if !ok {
+			continue
+		}
+		hits = append(hits, searchHit[T]{item, prefix + item.label, score, match})
+	}
+	return hits
+}
+
+/* globalSearch fills the results menu with the leaves matching query, ranked by score */
+func (rootmenu *Menu[T]) globalSearch(query string) *Menu[T] {
+	if rootmenu.results == nil {
+		rootmenu.results = MakeMenu[T](rootmenu.ctxmenu)
+		rootmenu.results.global = true
+	}
+	results := rootmenu.results
+	results.setFilter(true, query)
+
+	hits := rootmenu.searchLeaves(query, "", nil)
+	slices.SortStableFunc(hits, func(a, b searchHit[T]) int {
+		return b.score - a.score
+	})
+
+	results.items = results.items[:0]
+	for _, hit := range hits {
+		item := &Item[T]{
+			parent:      results,
+			output:      hit.item.output,
+			label:       hit.path,
+			icon:        hit.item.icon,
+			kind:        hit.item.kind,
+			match:       hit.match,
+			target:      hit.item,
+			mnemonicIdx: -1,
+		}
+		item.measure()
+		results.items = append(results.items, item)
+	}
+	results.selected = -1
+	return results
+}
+
+/* showResults shows the results of the global search in place of the root menu */
+func (rootmenu *Menu[T]) showResults(query string) (*Menu[T], error) {
+	results := rootmenu.globalSearch(query)
+	results.x = rootmenu.x
+	results.y = rootmenu.y
+	rootmenu.hide()
+	return results, results.show(nil)
+}
+
+/* hideResults closes the global search and shows the root menu again */
+func (rootmenu *Menu[T]) hideResults() error {
+	rootmenu.results.hide()
+	return rootmenu.show(nil)
+}
+
+/* index of item inside its menu, -1 if removed */
+func (item *Item[T]) index() int {
+	return slices.Index(item.parent.items, item)
+}
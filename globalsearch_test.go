@@ -0,0 +1,68 @@
+package ctxmenu
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzy(t *testing.T) {
+	tests := []struct {
+		text, query string
+		match       []int /* nil if it does not match */
+	}{
+		{"Web Browser", "wb", []int{0, 4}},
+		{"Web Browser", "br", []int{4, 5}},
+		{"Web Browser", "WEBB", []int{0, 1, 2, 4}},
+		{"Settings Manager", "sr", []int{0, 15}},
+		{"Settings Manager", "sm", []int{0, 9}},
+		{"Café", "cafe", []int{0, 1, 2, 3}},
+		{"Terminal", "mt", nil},
+		{"Terminal", "x", nil},
+	}
+	for _, test := range tests {
+		_, match, ok := fuzzy(test.text, test.query)
+		if ok != (test.match != nil) {
+			t.Errorf("fuzzy(%q, %q) matched %v, want %v", test.text, test.query, ok, test.match != nil)
+			continue
+		}
+		if !reflect.DeepEqual(match, test.match) {
+			t.Errorf("fuzzy(%q, %q) matched %v, want %v", test.text, test.query, match, test.match)
+		}
+	}
+
+	/* consecutive characters and word starts rank higher */
+	if a, b := score("Firefox", "fire"), score("Fine Artwork Tree", "fire"); a <= b {
+		t.Errorf("consecutive match scored %d, not higher than %d", a, b)
+	}
+	if a, b := score("Text Editor", "te"), score("Kate", "te"); a <= b {
+		t.Errorf("word start scored %d, not higher than %d", a, b)
+	}
+}
+
+func score(text, query string) int {
+	n, _, _ := fuzzy(text, query)
+	return n
+}
+
+func TestSearchLeaves(t *testing.T) {
+	ctxmenu := &ContextMenu{}
+	root := &Menu[string]{ctxmenu: ctxmenu}
+	settings := &Item[string]{parent: root, label: "Settings Manager", output: "settings"}
+	browser := &Item[string]{parent: root, label: "Browser", output: "browser", keywords: []string{"internet"}}
+	root.items = []*Item[string]{settings, browser}
+
+	/* a gappy match scoring below zero is still a match */
+	alphabet := &Item[string]{parent: root, label: "abcdefghijklmnop", output: "alphabet"}
+	root.items = append(root.items, alphabet)
+	if n := score(alphabet.label, "adgjmp"); n >= 0 {
+		t.Fatalf("expected a negative score, got %d", n)
+	}
+	hits := root.searchLeaves("adgjmp", "", nil)
+	if len(hits) != 1 || hits[0].item != alphabet {
+		t.Errorf("searchLeaves(adgjmp) = %v, want the alphabet", hits)
+	}
+	hits = root.searchLeaves("inet", "", nil)
+	if len(hits) != 1 || hits[0].item != browser || hits[0].match != nil {
+		t.Errorf("searchLeaves(inet) = %v, want Browser by keyword", hits)
+	}
+}
@@ -0,0 +1,107 @@
+package ctxmenu
+
+import (
+	"image"
+	"iter"
+)
+
+type Layout int
+
+/* enum for arranging menus taller than the display */
+const (
+	LayoutScroll  Layout = iota /* one column, scrolled with overflow-arrows */
+	LayoutColumns               /* items wrap into as many columns as needed */
+)
+
+/* placement is an item in sight and its area inside the menu, excluding the border */
+type placement[T comparable] struct {
+	index int /* index in items, -1 for overflow-items */
+	item  *Item[T]
+	rect  image.Rectangle
+}
+
+/* wrap the shown items into columns fitting in height pixels, returns the height of the tallest column */
+func (menu *Menu[T]) wrapColumns(height int) int {
+	menu.columns = []int{0}
+	colh, maxh := 0, 0
+	for n, i := range menu.shown {
+		item := menu.items[i]
+		if colh > 0 && colh+item.h > height {
+			menu.columns = append(menu.columns, n)
+			colh = 0
+		}
+		colh += item.h
+		maxh = max(maxh, colh)
+	}
+	return maxh
+}
+
+/* placeItems yields the items in sight in the order of shown, column by column */
+func (menu *Menu[T]) placeItems(withOverflow bool) iter.Seq[placement[T]] {
+	return func(yield func(placement[T]) bool) {
+		bw := menu.ctxmenu.BorderSize
+		top := bw + menu.fieldHeight()
+		x, y := bw, top
+		w := menu.w - bw*2
+		if len(menu.columns) > 1 {
+			w = menu.colw
+		}
+		col, pos := 0, 0
+		for i, item := range menu.visibleItems(withOverflow) {
+			if i != -1 && col+1 < len(menu.columns) && pos == menu.columns[col+1] {
+				col++
+				x += menu.colw
+				y = top
+			}
+			if !yield(placement[T]{i, item, image.Rect(x, y, x+w, y+item.h)}) {
+				return
+			}
+			y += item.h
+			if i != -1 {
+				pos++
+			}
+		}
+	}
+}
+
+/* get the area of the item at index inside the window, false if it is out of sight */
+func (menu *Menu[T]) itemRect(index int) (image.Rectangle, bool) {
+	for p := range menu.placeItems(true) {
+		if p.index != -1 && p.index == index {
+			return p.rect, true
+		}
+	}
+	return image.Rectangle{}, false
+}
+
+/* get the selectable item in the column next to the selected one in dir, closest to its height; -1 if there is none */
+func (menu *Menu[T]) columnitem(dir int) int {
+	if len(menu.columns) < 2 || menu.selected == -1 {
+		return -1
+	}
+	cur, ok := menu.itemRect(menu.selected)
+	if !ok {
+		return -1
+	}
+	x := cur.Min.X + dir*menu.colw
+	center := (cur.Min.Y + cur.Max.Y) / 2
+
+	found, dist := -1, 0
+	for p := range menu.placeItems(false) {
+		if p.rect.Min.X != x || !p.item.selectable() {
+			continue
+		}
+		d := abs((p.rect.Min.Y+p.rect.Max.Y)/2 - center)
+		if found == -1 || d < dist {
+			found, dist = p.index, d
+		}
+	}
+	return found
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,90 @@
+package ctxmenu
+
+/*
+ * The functions below may be called from any goroutine while Run is executing.
+ * Icons are loaded by the caller, the change itself is applied by the event loop
+ * which relayouts and redraws the menu if it is shown, submenus opened by the user stay open.
+ * Changes made while Run is not executing stay queued until it runs again.
+ */
+
+/* Insert adds an item at index to menu, the item is appended if index is out of range;
+ * the returned item is only in the menu once the event loop has applied the insertion */
+func (menu *Menu[T]) Insert(index int, label string, output T, imagefile string) (*Item[T], error) {
+	item, err := menu.newItem(label, output, imagefile)
+	if err != nil {
+		return nil, err
+	}
+
+	menu.ctxmenu.post(func() {
+		item.measure()
+		if index < 0 || index > len(menu.items) {
+			index = len(menu.items)
+		}
+		if menu.selected >= index {
+			menu.selected++
+		}
+		menu.items = append(menu.items[:index], append([]*Item[T]{item}, menu.items[index:]...)...)
+		menu.refresh()
+	})
+	return item, nil
+}
+
+/* Remove removes the item from its menu */
+func (item *Item[T]) Remove() {
+	item.parent.ctxmenu.post(item.remove)
+}
+
+/* remove the item from its menu, on the event loop only */
+func (item *Item[T]) remove() {
+	menu := item.parent
+	index := item.index()
+	if index == -1 {
+		return
+	}
+	if item.submenu != nil && item.submenu.mapped {
+		item.submenu.hide()
+	}
+	switch {
+	case menu.selected == index:
+		menu.selected = -1
+	case menu.selected > index:
+		menu.selected--
+	}
+	menu.items = append(menu.items[:index], menu.items[index+1:]...)
+	menu.refresh()
+}
+
+/* SetLabel replaces the label of the item, '&' marks the mnemonic */
+func (item *Item[T]) SetLabel(label string) {
+	item.parent.ctxmenu.post(func() {
+		item.label, item.mnemonic, item.mnemonicIdx = parseMnemonic(label)
+		item.automnemonic = false
+		item.measure()
+		item.parent.refresh()
+	})
+}
+
+/* SetIcon replaces the icon of the item, an empty imagefile removes it */
+func (item *Item[T]) SetIcon(imagefile string) error {
+	icon, err := item.parent.ctxmenu.loadIcon(imagefile)
+	if err != nil {
+		return err
+	}
+	item.parent.ctxmenu.post(func() {
+		item.icon = icon
+		item.measure()
+		item.parent.refresh()
+	})
+	return nil
+}
+
+/* relayout and redraw menu after its items changed, if it is shown */
+func (menu *Menu[T]) refresh() {
+	menu.itemsChanged = true
+	if !menu.mapped {
+		return
+	}
+	menu.layout(menu.caller)
+	menu.animateRelayout()
+	menu.draw()
+}
@@ -0,0 +1,87 @@
+package ctxmenu
+
+import (
+	"unicode"
+)
+
+/* mnemonicKey returns chr without case, accents are kept so typing 'ü' does not fire 'u' */
+func mnemonicKey(chr rune) rune {
+	return unicode.ToLower(chr)
+}
+
+/* parseMnemonic strips the '&'-marker in front of a letter or digit from label; "&&" is a literal '&',
+ * and so is a '&' in front of anything else ("Save & Quit") or between capitals ("R&D", "AT&T") */
+func parseMnemonic(label string) (text string, mnemonic rune, index int) {
+	index = -1
+	runes := []rune(label)
+	out := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '&' && i+1 < len(runes) {
+			switch next := runes[i+1]; {
+			case next == '&':
+				i++
+			case i > 0 && unicode.IsUpper(runes[i-1]) && unicode.IsUpper(next):
+				/* an abbreviation */
+			case unicode.IsLetter(next) || unicode.IsDigit(next):
+				i++
+				if index == -1 {
+					index = len(out)
+					mnemonic = mnemonicKey(next)
+				}
+			}
+		}
+		out = append(out, runes[i])
+	}
+	return string(out), mnemonic, index
+}
+
+/* assignMnemonics gives every item without explicit mnemonic the first unused letter of its label */
+func (menu *Menu[T]) assignMnemonics() {
+	used := make(map[rune]bool)
+	for _, item := range menu.items {
+		if item.automnemonic {
+			item.mnemonic = 0
+			item.mnemonicIdx = -1
+			item.automnemonic = false
+			item.labeltex = nil
+		}
+		if item.mnemonic != 0 {
+			used[item.mnemonic] = true
+		}
+	}
+	if !menu.ctxmenu.AutoMnemonics {
+		return
+	}
+	for _, item := range menu.items {
+		if item.mnemonic != 0 || item.isSeparator() || item.header {
+			continue
+		}
+		for i, chr := range []rune(item.label) {
+			key := mnemonicKey(chr)
+			if (unicode.IsLetter(key) || unicode.IsDigit(key)) && !used[key] {
+				used[key] = true
+				item.mnemonic = key
+				item.mnemonicIdx = i
+				item.automnemonic = true
+				item.labeltex = nil
+				break
+			}
+		}
+	}
+}
+
+/* get item in menu whose mnemonic is the typed text, -1 if none;
+ * assigned mnemonics only count with alt held, plain typing goes to the search field */
+func (menu *Menu[T]) mnemonicitem(text string, alt bool) int {
+	runes := []rune(text)
+	if len(runes) != 1 {
+		return -1
+	}
+	key := mnemonicKey(runes[0])
+	for i, item := range menu.items {
+		if item.mnemonic != 0 && item.mnemonic == key && item.selectable() && (alt || !item.automnemonic) {
+			return i
+		}
+	}
+	return -1
+}
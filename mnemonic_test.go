@@ -0,0 +1,31 @@
+package ctxmenu
+
+import "testing"
+
+func TestParseMnemonic(t *testing.T) {
+	tests := []struct {
+		label    string
+		text     string
+		mnemonic rune
+		index    int
+	}{
+		{"&File", "File", 'f', 0},
+		{"E&xit", "Exit", 'x', 1},
+		{"Save &As", "Save As", 'a', 5},
+		{"&1 Recent", "1 Recent", '1', 0},
+		{"R&D", "R&D", 0, -1},
+		{"AT&T &Mobile", "AT&T Mobile", 'm', 5},
+		{"Save & Quit", "Save & Quit", 0, -1},
+		{"Fish && Chips", "Fish & Chips", 0, -1},
+		{"&&&Copy", "&Copy", 'c', 1},
+		{"&Open &Recent", "Open Recent", 'o', 0},
+		{"trailing&", "trailing&", 0, -1},
+	}
+	for _, test := range tests {
+		text, mnemonic, index := parseMnemonic(test.label)
+		if text != test.text || mnemonic != test.mnemonic || index != test.index {
+			t.Errorf("parseMnemonic(%q) = %q, %q, %d, want %q, %q, %d", test.label,
+				text, mnemonic, index, test.text, test.mnemonic, test.index)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package ctxmenu
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+/* label of the placeholder shown while a submenu is loading */
+const loadingLabel = "Loading…"
+
+/* SetPipe fills the submenu of item with the output of command in the text format, run by the shell
+ * each time the submenu is opened, or only the first time if cache is set */
+func SetPipe(item *Item[string], command string, cache bool) {
+	if item.submenu == nil {
+		item.setSubmenu(MakeMenu[string](item.parent.ctxmenu))
+	}
+	timeout := item.parent.ctxmenu.PipeTimeout
+	sub := item.submenu
+	item.cache = cache
+	item.loaded = false
+	item.loader = func() (func() error, error) {
+		entries, err := runPipe(command, timeout)
+		if err != nil {
+			return nil, err
+		}
+		return func() error {
+			for _, e := range entries {
+				if _, err := e.append(sub); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, nil
+	}
+}
+
+/* run command in the shell and parse its output, it is killed after timeout unless 0 */
+func runPipe(command string, timeout time.Duration) ([]entry, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var buf bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = &buf
+	cmd.Stderr = os.Stderr
+
+	/* kill the whole process group, children of the shell would keep stdout open */
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("%s: timed out after %v", command, timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseEntries(&buf)
+}
+
+/* load the submenu of item in the background, showing a placeholder until it is done */
+func (item *Item[T]) load() {
+	if item.loader == nil || item.loading || item.cache && item.loaded {
+		return
+	}
+	ctxmenu := item.parent.ctxmenu
+	sub := item.submenu
+	loader := item.loader
+
+	item.loading = true
+	sub.placeholder(loadingLabel)
+
+	go func() {
+		update, err := loader()
+		ctxmenu.post(func() {
+			item.loading = false
+			sub.clear()
+			if err == nil {
+				err = update()
+			}
+			if err != nil {
+				sub.placeholder(fmt.Sprintf("error: %v", err))
+			} else {
+				item.loaded = true
+			}
+			sub.refresh()
+		})
+	}()
+}
+
+/* replace the items of menu by a disabled item showing label */
+func (menu *Menu[T]) placeholder(label string) {
+	var zero T
+	menu.clear()
+	item, _ := menu.AppendItem(label, zero, "")
+	item.SetDisabled(true)
+}
+
+/* remove all items of menu */
+func (menu *Menu[T]) clear() {
+	menu.hideChildren(nil)
+	menu.items = nil
+	menu.selected = -1
+	menu.itemsChanged = true
+}
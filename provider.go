@@ -0,0 +1,32 @@
+package ctxmenu
+
+import (
+	"fmt"
+)
+
+/* Provider fills sub, the submenu of parent, right before it is shown */
+type Provider[T comparable] func(parent *Item[T], sub *Menu[T]) error
+
+/* SetProvider lets provider fill the submenu of item each time it is opened, or only the first time if cache is set */
+func (item *Item[T]) SetProvider(provider Provider[T], cache bool) {
+	if item.submenu == nil {
+		item.setSubmenu(MakeMenu[T](item.parent.ctxmenu))
+	}
+	item.provider = provider
+	item.cache = cache
+	item.loaded = false
+}
+
+/* fill the submenu of item by its provider, an error is shown in place of the items */
+func (item *Item[T]) provide() {
+	if item.provider == nil || item.cache && item.loaded {
+		return
+	}
+	sub := item.submenu
+	sub.clear()
+	if err := item.provider(item, sub); err != nil {
+		sub.placeholder(fmt.Sprintf("error: %v", err))
+		return
+	}
+	item.loaded = true
+}
@@ -0,0 +1,67 @@
+package ctxmenu
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/* post queues fn to run on the event loop and wakes it up, safe to call from any goroutine */
+func (ctxmenu *ContextMenu) post(fn func()) {
+	ctxmenu.queueLock.Lock()
+	ctxmenu.queue = append(ctxmenu.queue, fn)
+	ctxmenu.queueLock.Unlock()
+
+	sdl.PushEvent(&sdl.UserEvent{Type: ctxmenu.wakeEvent})
+}
+
+/* call runs fn on the event loop and returns its error, or ErrExited if Run returns before */
+func (ctxmenu *ContextMenu) call(fn func() error) error {
+	ctxmenu.queueLock.Lock()
+	exited := ctxmenu.exited
+	ctxmenu.queueLock.Unlock()
+
+	done := make(chan error, 1)
+	ctxmenu.post(func() {
+		done <- fn()
+	})
+	select {
+	case err := <-done:
+		return err
+	case <-exited:
+		select {
+		case err := <-done:
+			return err
+		default:
+			return ErrExited
+		}
+	}
+}
+
+/* startLoop renews the channel closed when Run returns, if it was closed by a previous Run */
+func (ctxmenu *ContextMenu) startLoop() {
+	ctxmenu.queueLock.Lock()
+	defer ctxmenu.queueLock.Unlock()
+	select {
+	case <-ctxmenu.exited:
+		ctxmenu.exited = make(chan struct{})
+	default:
+	}
+}
+
+/* stopLoop wakes up the callers waiting for the event loop */
+func (ctxmenu *ContextMenu) stopLoop() {
+	ctxmenu.queueLock.Lock()
+	defer ctxmenu.queueLock.Unlock()
+	close(ctxmenu.exited)
+}
+
+/* flush runs all queued functions, on the event loop only */
+func (ctxmenu *ContextMenu) flush() {
+	ctxmenu.queueLock.Lock()
+	queue := ctxmenu.queue
+	ctxmenu.queue = nil
+	ctxmenu.queueLock.Unlock()
+
+	for _, fn := range queue {
+		fn()
+	}
+}
@@ -0,0 +1,196 @@
+package ctxmenu
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+ * Color schemes of other programs are imported as theme variables, the colors of Config refer to them:
+ *
+ *   wal     ~/.cache/wal/colors.json written by pywal: @background, @foreground, @cursor, @color0 to @color15
+ *   base16  a base16 scheme in YAML: @base00 to @base0F
+ *   gtk     @define-color in gtk.css, ~/.config/gtk-3.0/gtk.css by default: @theme_bg_color and so on
+ */
+
+/* LoadScheme imports a color scheme into conf, source is "wal", "base16:FILE" or "gtk",
+ * optionally followed by ":FILE" to read another file than the default */
+func LoadScheme(conf *Config, source string) error {
+	format, path, _ := strings.Cut(source, ":")
+	switch format {
+	case "wal":
+		if path == "" {
+			path = filepath.Join(userDir("XDG_CACHE_HOME", ".cache"), "wal", "colors.json")
+		}
+		return LoadWal(conf, path)
+	case "base16":
+		if path == "" {
+			return fmt.Errorf("base16 needs a scheme file: base16:FILE")
+		}
+		return LoadBase16(conf, path)
+	case "gtk":
+		if path == "" {
+			path = filepath.Join(userDir("XDG_CONFIG_HOME", ".config"), "gtk-3.0", "gtk.css")
+		}
+		return LoadGTK(conf, path)
+	default:
+		return fmt.Errorf("unknown color scheme format: %s, expected wal, base16 or gtk", format)
+	}
+}
+
+/* LoadWal imports the colors.json written by pywal */
+func LoadWal(conf *Config, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var scheme struct {
+		Special map[string]string `json:"special"`
+		Colors  map[string]string `json:"colors"`
+	}
+	if err := json.Unmarshal(content, &scheme); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	vars := make(palette)
+	for name, value := range scheme.Special {
+		vars[name] = value
+	}
+	for name, value := range scheme.Colors {
+		vars[name] = value
+	}
+	return conf.applyScheme(path, vars, schemeRoles{
+		background:    []string{"background"},
+		foreground:    []string{"foreground"},
+		selbackground: []string{"color4"},
+		selforeground: []string{"background"},
+		separator:     []string{"color8"},
+		border:        []string{"color8"},
+		disabled:      []string{"color8"},
+	})
+}
+
+/* LoadBase16 imports a base16 scheme, a YAML-file with the keys base00 to base0F */
+func LoadBase16(conf *Config, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	/* only the flat "key: value" lines are of interest, also when nested under "palette:" */
+	vars := make(palette)
+	scan := bufio.NewScanner(file)
+	for scan.Scan() {
+		key, value, ok := strings.Cut(scan.Text(), ":")
+		key = strings.TrimSpace(key)
+		if !ok || len(key) != 6 || !strings.HasPrefix(strings.ToLower(key), "base") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if quote := value[:min(len(value), 1)]; quote == `"` || quote == "'" {
+			value, _, _ = strings.Cut(value[1:], quote)
+		} else {
+			value, _, _ = strings.Cut(value, " #")
+		}
+		if !strings.HasPrefix(value, "#") {
+			value = "#" + value
+		}
+		vars[strings.ToLower(key[:4])+strings.ToUpper(key[4:])] = value
+	}
+	if err := scan.Err(); err != nil {
+		return err
+	}
+	return conf.applyScheme(path, vars, schemeRoles{
+		background:    []string{"base00"},
+		foreground:    []string{"base05"},
+		selbackground: []string{"base0D"},
+		selforeground: []string{"base00"},
+		separator:     []string{"base02"},
+		border:        []string{"base01"},
+		disabled:      []string{"base03"},
+	})
+}
+
+/* LoadGTK imports the colors defined by @define-color in a GTK-stylesheet */
+func LoadGTK(conf *Config, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	text, err := stripComments(string(content))
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	vars := make(palette)
+	for stmt := range strings.SplitSeq(text, ";") {
+		_, def, ok := strings.Cut(stmt, "@define-color")
+		if !ok {
+			continue
+		}
+		name, value, _ := strings.Cut(strings.TrimSpace(def), " ")
+		vars[name] = strings.TrimSpace(value)
+	}
+	return conf.applyScheme(path, vars, schemeRoles{
+		background:    []string{"theme_bg_color", "window_bg_color"},
+		foreground:    []string{"theme_fg_color", "window_fg_color"},
+		selbackground: []string{"theme_selected_bg_color", "accent_bg_color"},
+		selforeground: []string{"theme_selected_fg_color", "accent_fg_color"},
+		separator:     []string{"borders"},
+		border:        []string{"borders"},
+		disabled:      []string{"insensitive_fg_color", "unfocused_insensitive_color"},
+	})
+}
+
+/* schemeRoles names the variables of a scheme used for the colors of Config, the first one defined is used */
+type schemeRoles struct {
+	background, foreground       []string
+	selbackground, selforeground []string
+	separator, border, disabled  []string
+}
+
+/* add vars to the theme variables of conf and refer to them by the colors of conf,
+ * colors whose variable is missing or cannot be parsed are kept */
+func (conf *Config) applyScheme(path string, vars palette, roles schemeRoles) error {
+	if len(vars) == 0 {
+		return fmt.Errorf("%s: no colors found", path)
+	}
+	if conf.Colors == nil {
+		conf.Colors = make(map[string]string)
+	}
+	for name, value := range vars {
+		conf.Colors[name] = value
+	}
+	for _, role := range []struct {
+		names []string
+		field *string
+	}{
+		{roles.background, &conf.BackgroundColor},
+		{roles.foreground, &conf.ForegroundColor},
+		{roles.selbackground, &conf.SelbackgroundColor},
+		{roles.selforeground, &conf.SelforegroundColor},
+		{roles.separator, &conf.SeparatorColor},
+		{roles.border, &conf.BorderColor},
+		{roles.disabled, &conf.DisabledColor},
+	} {
+		for _, name := range role.names {
+			if _, err := palette(conf.Colors).parse("@" + name); err == nil {
+				*role.field = "@" + name
+				break
+			}
+		}
+	}
+	return nil
+}
+
+/* get the directory in the environment variable env, or name in the home directory */
+func userDir(env, name string) string {
+	if dir := os.Getenv(env); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, name)
+}
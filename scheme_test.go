@@ -0,0 +1,39 @@
+package ctxmenu
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadGTK(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gtk.css")
+	css := `
+/* @define-color theme_bg_color #ff0000; commented out */
+@define-color theme_bg_color #242424;
+@define-color accent_bg_color /* inline */ #3584e4;
+window { background: @theme_bg_color; }
+`
+	if err := os.WriteFile(path, []byte(css), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var conf Config
+	if err := LoadGTK(&conf, path); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"theme_bg_color": "#242424", "accent_bg_color": "#3584e4"}
+	if !reflect.DeepEqual(conf.Colors, want) {
+		t.Errorf("Colors = %v, want %v", conf.Colors, want)
+	}
+	if conf.BackgroundColor != "@theme_bg_color" || conf.SelbackgroundColor != "@accent_bg_color" {
+		t.Errorf("BackgroundColor = %q, SelbackgroundColor = %q", conf.BackgroundColor, conf.SelbackgroundColor)
+	}
+
+	if err := os.WriteFile(path, []byte("@define-color a #fff; /* unterminated"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadGTK(&conf, path); err == nil {
+		t.Error("expected an error for an unterminated comment")
+	}
+}
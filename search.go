@@ -0,0 +1,183 @@
+package ctxmenu
+
+import (
+	"image"
+	"image/draw"
+	"strings"
+	"unicode"
+
+	"github.com/veandco/go-sdl2/sdl"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+/* setFilter sets the text of the search field, the menu has to be shown again to apply it */
+func (menu *Menu[T]) setFilter(searching bool, text string) {
+	menu.searching = searching || text != ""
+	menu.filter = text
+	menu.itemsChanged = true
+}
+
+/* applyFilter collects the items matching the search field and selects the first match */
+func (menu *Menu[T]) applyFilter() {
+	menu.shown = menu.shown[:0]
+	for i, item := range menu.items {
+		if menu.global {
+			/* search results are already filtered */
+			menu.shown = append(menu.shown, i)
+			continue
+		}
+		item.match = nil
+		if menu.filter == "" {
+			menu.shown = append(menu.shown, i)
+			continue
+		}
+		if !item.selectable() {
+			continue
+		}
+		if start, end := matchlabel(item.label, menu.filter); start != -1 {
+			for pos := start; pos < end; pos++ {
+				item.match = append(item.match, pos)
+			}
+			menu.shown = append(menu.shown, i)
+		}
+	}
+	if menu.global {
+		menu.selected = menu.itemcycle(ItemFirst)
+	} else if menu.filter != "" && (menu.selected == -1 || menu.items[menu.selected].match == nil) {
+		menu.selected = menu.matchitem(menu.filter, 0)
+	}
+}
+
+/* fold returns text without case and accents, and for each of its runes the index of the rune in text it stems from */
+func fold(text string) ([]rune, []int) {
+	folder := cases.Fold()
+	var folded []rune
+	var origin []int
+	for i, chr := range []rune(text) {
+		/* decompose 'é' into 'e' and a combining accent, which is dropped */
+		for _, f := range folder.String(norm.NFD.String(string(chr))) {
+			if unicode.Is(unicode.Mn, f) {
+				continue
+			}
+			folded = append(folded, f)
+			origin = append(origin, i)
+		}
+	}
+	return folded, origin
+}
+
+/* foldString returns text without case and accents */
+func foldString(text string) string {
+	folded, _ := fold(text)
+	return string(folded)
+}
+
+/* matchlabel finds text in label ignoring case and accents, returns the matched range of runes in label or -1 */
+func matchlabel(label, text string) (int, int) {
+	hay, origin := fold(label)
+	needle, _ := fold(text)
+	if len(needle) == 0 {
+		return 0, 0
+	}
+	for i := 0; i+len(needle) <= len(hay); i++ {
+		if string(hay[i:i+len(needle)]) == string(needle) {
+			return origin[i], origin[i+len(needle)-1] + 1
+		}
+	}
+	return -1, -1
+}
+
+/* get item in menu matching text from given direction (or from beginning, if dir = 0) */
+func (menu *Menu[T]) matchitem(text string, dir int) int {
+	n := len(menu.items)
+	step := 1
+	if dir < 0 {
+		step = -1
+	}
+	item := 0
+	if dir != 0 && menu.selected != -1 {
+		item = menu.selected + step
+	} else if dir < 0 {
+		item = n - 1
+	}
+
+	/* find next item from selected item, wrapping around */
+	for range n {
+		item = (item%n + n) % n
+		if menu.items[item].selectable() {
+			if start, _ := matchlabel(menu.items[item].label, text); start != -1 {
+				return item
+			}
+		}
+		item += step
+	}
+	return -1
+}
+
+/* get the height of the search field, 0 if not searching */
+func (menu *Menu[T]) fieldHeight() int {
+	if !menu.searching {
+		return 0
+	}
+	return menu.ctxmenu.font.Metrics().Height.Ceil() + menu.ctxmenu.PaddingY*2 + 1
+}
+
+/* draw the search field on top of the menu */
+func (menu *Menu[T]) drawField() {
+	h := menu.fieldHeight()
+	if h == 0 {
+		return
+	}
+	bw := menu.ctxmenu.BorderSize
+	img := &SubImage{menu.surf, image.Rect(bw, bw, menu.w-bw, bw+h)}
+	fg := image.NewUniform(menu.ctxmenu.normal.Foreground)
+
+	draw.Draw(img, img.Bounds(), image.NewUniform(opaque(menu.ctxmenu.normal.Background)), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, h-1, img.Rect.Dx(), h), image.NewUniform(menu.ctxmenu.separator), image.Point{}, draw.Over)
+
+	/* the text being composed by an input method follows the filter, underlined */
+	text := menu.filter + menu.ctxmenu.composition
+	compose := menu.ctxmenu.messureText(menu.ctxmenu.composition)
+
+	/* show the end of the text if it does not fit */
+	textH := menu.ctxmenu.font.Metrics().Height.Ceil()
+	textW := menu.ctxmenu.messureText(text)
+	avail := img.Rect.Dx() - menu.ctxmenu.PaddingX*2 - 1
+	mask := image.NewAlpha(image.Rect(0, 0, textW, textH))
+	menu.ctxmenu.drawText(mask, text)
+	x := menu.ctxmenu.PaddingX + min(avail-textW, 0)
+	y := menu.ctxmenu.PaddingY
+	draw.DrawMask(img, mask.Bounds().Add(image.Point{x, y}), fg, image.Point{}, mask, image.Point{}, draw.Over)
+	if compose > 0 {
+		base := y + menu.ctxmenu.font.Metrics().Ascent.Ceil() + 1
+		draw.Draw(img, image.Rect(x+textW-compose, base, x+textW, base+1), fg, image.Point{}, draw.Src)
+	}
+
+	/* caret */
+	draw.Draw(img, image.Rect(x+textW, y, x+textW+1, y+textH), fg, image.Point{}, draw.Src)
+
+	/* let the input method place its candidate window below the field */
+	sdl.SetTextInputRect(&sdl.Rect{
+		X: int32(bw + x + textW),
+		Y: int32(bw),
+		W: 1,
+		H: int32(h),
+	})
+}
+
+/* highlight the characters of an item matching the search field */
+func (item *Item[T]) drawMatch(img draw.Image, x, y int, color ColorPair) {
+	face := item.face()
+	runes := []rune(item.label)
+	h := face.Metrics().Height.Ceil()
+	tint := image.NewUniform(mixColor(color.Foreground, color.Background, 0.25))
+	for _, pos := range item.match {
+		if !strings.HasPrefix(item.text, string(runes[:pos+1])) {
+			return /* truncated away */
+		}
+		x0 := x + messureFaceText(face, string(runes[:pos]))
+		x1 := x0 + messureFaceText(face, string(runes[pos]))
+		draw.Draw(img, image.Rect(x0, y, x1, y+h), tint, image.Point{}, draw.Src)
+	}
+}
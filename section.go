@@ -0,0 +1,77 @@
+package ctxmenu
+
+import (
+	"image"
+	"image/draw"
+)
+
+/* SetHeader makes the item a section header, drawn in the header font and not selectable */
+func (item *Item[T]) SetHeader(header bool) {
+	item.header = header
+	item.clearMnemonic()
+	item.measure()
+	item.parent.itemsChanged = true
+}
+
+/* SetSeparator makes the item a separator with its label as inline title, "── Recent ──" */
+func (item *Item[T]) SetSeparator(separator bool) {
+	item.separator = separator
+	item.clearMnemonic()
+	item.measure()
+	item.parent.itemsChanged = true
+}
+
+/* isSeparator reports whether the item is a separator, with or without title */
+func (item *Item[T]) isSeparator() bool {
+	return item.label == "" || item.separator
+}
+
+/* remove the mnemonic of an item which cannot be selected */
+func (item *Item[T]) clearMnemonic() {
+	if item.header || item.separator {
+		item.mnemonic = 0
+		item.mnemonicIdx = -1
+		item.automnemonic = false
+	}
+}
+
+/* measure a separator with title, leaving room for a line on both sides */
+func (item *Item[T]) measureSeparator() {
+	ctxmenu := item.parent.ctxmenu
+	pad := item.padding()
+	face := item.face()
+	line := ctxmenu.SeperatorLength + pad.X*2
+
+	item.h = face.Metrics().Height.Ceil() + pad.Y*2
+	item.text = item.label
+	if ctxmenu.MaxItemWidth > 0 {
+		item.text = ctxmenu.truncateText(face, item.label, ctxmenu.MaxItemWidth-item.w-line*2)
+	}
+	item.w += messureFaceText(face, item.text) + line*2
+}
+
+/* draw a separator with its title centered between two lines */
+func (item *Item[T]) drawSeparator(img *SubImage) {
+	ctxmenu := item.parent.ctxmenu
+	pad := item.padding()
+	color := item.colors(false)
+	face := item.face()
+	w := img.Rect.Dx()
+
+	textH := face.Metrics().Height.Ceil()
+	textW := messureFaceText(face, item.text)
+	if item.labeltex == nil {
+		item.labeltex = image.NewAlpha(image.Rect(0, 0, textW, textH))
+		drawFaceText(face, item.labeltex, item.text)
+	}
+	x := w/2 - textW/2
+	y := item.h/2 - textH/2
+	draw.DrawMask(img, item.labeltex.Bounds().Add(image.Point{x, y}), image.NewUniform(color.Foreground), image.Point{}, item.labeltex, image.Point{}, draw.Over)
+
+	/* lines left and right of the title */
+	sep := image.NewUniform(ctxmenu.separator)
+	left := pad.X + ctxmenu.SeperatorLength
+	right := w - left
+	draw.Draw(img, image.Rect(left, item.h/2, x-pad.X, item.h/2+1), sep, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(x+textW+pad.X, item.h/2, right, item.h/2+1), sep, image.Point{}, draw.Src)
+}
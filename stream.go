@@ -0,0 +1,244 @@
+package ctxmenu
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*
+ * A stream starts with a menu in the text format, terminated by a line ".".
+ * Each following line is a command applied to the open menu, fields separated by tabs:
+ *
+ *   add     ADDR  FIELDS...   append an item to the submenu of ADDR
+ *   update  ADDR  FIELDS...   replace the item at ADDR, keeping its submenu
+ *   remove  ADDR              remove the item at ADDR
+ *   replace ADDR              replace the submenu of ADDR by the menu on the next lines, up to "."
+ *
+ * ADDR is "#id" for an item with field ID:id, or the labels leading to the item separated by '/'.
+ * An empty ADDR is the root menu.
+ */
+
+/* stream holds the state of a menu read by Stream */
+type stream struct {
+	root *Menu[string]
+	scan *bufio.Scanner
+	ids  map[string]*Item[string] /* items by their ID:-field */
+}
+
+/* Stream reads a menu in the text format from r up to a line ".", and returns a function
+ * applying the commands on the following lines to the open menu until r is exhausted or Run returns;
+ * invalid commands are passed to report, which may be nil, and skipped */
+func Stream(menu *Menu[string], r io.Reader) (follow func(report func(error)) error, err error) {
+	s := &stream{
+		root: menu,
+		scan: bufio.NewScanner(r),
+		ids:  make(map[string]*Item[string]),
+	}
+	entries, err := s.readEntries()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.appendEntries(menu, entries); err != nil {
+		return nil, err
+	}
+	return s.follow, nil
+}
+
+/* read entries up to a line "." or the end of input */
+func (s *stream) readEntries() ([]entry, error) {
+	var entries []entry
+	for s.scan.Scan() {
+		if s.scan.Text() == "." {
+			break
+		}
+		e, err := parseEntry(s.scan.Text(), '\t')
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, s.scan.Err()
+}
+
+/* append entries to menu and remember their ids */
+func (s *stream) appendEntries(menu *Menu[string], entries []entry) error {
+	for _, e := range entries {
+		item, err := e.append(menu)
+		if err != nil {
+			return err
+		}
+		if e.id != "" {
+			s.ids[e.id] = item
+		}
+	}
+	return nil
+}
+
+/* apply the commands read from the stream, each waits until the event loop has applied it;
+ * invalid commands are passed to report and skipped, it returns ErrExited once Run has returned */
+func (s *stream) follow(report func(error)) error {
+	if report == nil {
+		report = func(error) {}
+	}
+	for s.scan.Scan() {
+		line := s.scan.Text()
+		if line == "" {
+			continue
+		}
+		command, rest, _ := strings.Cut(line, "\t")
+		addr, fields, _ := strings.Cut(rest, "\t")
+
+		var apply func() error
+		switch command {
+		case "add", "update":
+			e, err := parseEntry(fields, '\t')
+			if err != nil {
+				report(fmt.Errorf("%s: %w", line, err))
+				continue
+			}
+			if command == "add" {
+				apply = func() error { return s.add(addr, e) }
+			} else {
+				apply = func() error { return s.update(addr, e) }
+			}
+		case "remove":
+			apply = func() error { return s.remove(addr) }
+		case "replace":
+			entries, err := s.readEntries()
+			if err != nil {
+				report(fmt.Errorf("%s: %w", line, err))
+				continue
+			}
+			apply = func() error { return s.replace(addr, entries) }
+		default:
+			report(fmt.Errorf("unknown command: %s", line))
+			continue
+		}
+
+		err := s.root.ctxmenu.call(apply)
+		if err == ErrExited {
+			return err
+		} else if err != nil {
+			report(fmt.Errorf("%s: %w", line, err))
+		}
+	}
+	return s.scan.Err()
+}
+
+/* find the item at addr */
+func (s *stream) find(addr string) (*Item[string], error) {
+	if addr == "" {
+		return nil, fmt.Errorf("empty address")
+	}
+	if id, ok := strings.CutPrefix(addr, "#"); ok {
+		item, ok := s.ids[id]
+		if !ok || item.index() == -1 {
+			return nil, fmt.Errorf("no item with id: %s", id)
+		}
+		return item, nil
+	}
+
+	menu := s.root
+	var item *Item[string]
+	for label := range strings.SplitSeq(addr, "/") {
+		if menu == nil {
+			return nil, fmt.Errorf("no such item: %s", addr)
+		}
+		item = nil
+		for _, it := range menu.items {
+			if it.label == label {
+				item = it
+				break
+			}
+		}
+		if item == nil {
+			return nil, fmt.Errorf("no such item: %s", addr)
+		}
+		menu = item.submenu
+	}
+	return item, nil
+}
+
+/* get the submenu of the item at addr, creating it if needed; the root menu if addr is empty */
+func (s *stream) submenu(addr string) (*Menu[string], error) {
+	if addr == "" {
+		return s.root, nil
+	}
+	item, err := s.find(addr)
+	if err != nil {
+		return nil, err
+	}
+	if item.submenu == nil {
+		item.setSubmenu(MakeMenu[string](item.parent.ctxmenu))
+		item.parent.refresh()
+	}
+	return item.submenu, nil
+}
+
+func (s *stream) add(addr string, e entry) error {
+	menu, err := s.submenu(addr)
+	if err != nil {
+		return err
+	}
+	if err := s.appendEntries(menu, []entry{e}); err != nil {
+		return err
+	}
+	menu.refresh()
+	return nil
+}
+
+func (s *stream) update(addr string, e entry) error {
+	old, err := s.find(addr)
+	if err != nil {
+		return err
+	}
+	menu := old.parent
+	item, err := menu.makeItem(e.label, e.output, e.imgpath)
+	if err != nil {
+		return err
+	}
+	e.apply(item)
+	if item.submenu == nil && old.submenu != nil {
+		item.setSubmenu(old.submenu)
+	} else if old.submenu != nil && old.submenu.mapped {
+		old.submenu.hide()
+	}
+	menu.items[old.index()] = item
+	for id, it := range s.ids {
+		if it == old {
+			delete(s.ids, id)
+			if e.id == "" {
+				e.id = id /* keep addressing the item by its id */
+			}
+		}
+	}
+	if e.id != "" {
+		s.ids[e.id] = item
+	}
+	menu.refresh()
+	return nil
+}
+
+func (s *stream) remove(addr string) error {
+	item, err := s.find(addr)
+	if err != nil {
+		return err
+	}
+	item.remove()
+	return nil
+}
+
+func (s *stream) replace(addr string, entries []entry) error {
+	menu, err := s.submenu(addr)
+	if err != nil {
+		return err
+	}
+	menu.clear()
+	if err := s.appendEntries(menu, entries); err != nil {
+		return err
+	}
+	menu.refresh()
+	return nil
+}
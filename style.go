@@ -0,0 +1,428 @@
+package ctxmenu
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+)
+
+/*
+ * A stylesheet overrides the colors, padding and font of Config for matching items and menus:
+ *
+ *   item { padding: 6 8; }
+ *   item:hover, .danger { background: #C01C28; foreground: #FFFFFF; }
+ *   header { font: sans:size=10:bold; foreground: #77767B; }
+ *   menu:depth(1) { border: @accent; }
+ *
+ * A selector is a type (item, header, separator, menu or *) followed by classes (.name),
+ * states (:hover, :disabled, :checked) and a depth (:depth(n), 0 is the root menu).
+ * The most specific matching rule wins, later rules win over earlier ones of equal specificity.
+ * Menus only have a border, items no border; padding and font are the same in all states,
+ * so they cannot be set under :hover, :disabled or :checked.
+ * "@define-color name value;" defines or overrides the theme variable @name for the rules below.
+ */
+
+/* style holds the properties set by a stylesheet, nil if unset */
+type style struct {
+	background *color.NRGBA
+	foreground *color.NRGBA
+	border     *color.NRGBA
+	padding    *image.Point
+	font       font.Face
+}
+
+/* merge the properties set in other into st */
+func (st *style) merge(other style) {
+	if other.background != nil {
+		st.background = other.background
+	}
+	if other.foreground != nil {
+		st.foreground = other.foreground
+	}
+	if other.border != nil {
+		st.border = other.border
+	}
+	if other.padding != nil {
+		st.padding = other.padding
+	}
+	if other.font != nil {
+		st.font = other.font
+	}
+}
+
+/* selector matches items or menus of a kind, with classes, states and depth */
+type selector struct {
+	kind    string /* item, header, separator, menu or empty for any */
+	classes []string
+	states  []string
+	depth   int /* -1 for any */
+}
+
+/* specificity of the selector, classes, states and depth weigh more than the type */
+func (sel selector) specificity() int {
+	n := (len(sel.classes) + len(sel.states)) * 10
+	if sel.depth != -1 {
+		n += 10
+	}
+	if sel.kind != "" {
+		n++
+	}
+	return n
+}
+
+/* matches reports whether sel applies to target */
+func (sel selector) matches(target selector) bool {
+	if sel.kind != "" && sel.kind != target.kind {
+		return false
+	}
+	if sel.depth != -1 && sel.depth != target.depth {
+		return false
+	}
+	for _, class := range sel.classes {
+		if !slices.Contains(target.classes, class) {
+			return false
+		}
+	}
+	for _, state := range sel.states {
+		if !slices.Contains(target.states, state) {
+			return false
+		}
+	}
+	return true
+}
+
+/* check reports properties of st which have no effect on what sel matches */
+func (sel selector) check(st style) error {
+	if sel.kind == "menu" {
+		if st.background != nil || st.foreground != nil || st.padding != nil || st.font != nil {
+			return fmt.Errorf("menu only has a border")
+		}
+	} else if sel.kind != "" && st.border != nil {
+		return fmt.Errorf("border only applies to menu")
+	}
+	if len(sel.states) > 0 && (st.padding != nil || st.font != nil) {
+		return fmt.Errorf("padding and font cannot change with the state")
+	}
+	return nil
+}
+
+type rule struct {
+	selector selector
+	style    style
+}
+
+/* stylesheet is a parsed stylesheet, rules are sorted by specificity */
+type stylesheet struct {
+	rules []rule
+}
+
+/* resolve the style of target, nil stylesheets set nothing */
+func (sheet *stylesheet) resolve(target selector) style {
+	var st style
+	if sheet == nil {
+		return st
+	}
+	for _, r := range sheet.rules {
+		if r.selector.matches(target) {
+			st.merge(r.style)
+		}
+	}
+	return st
+}
+
+/* loadStylesheet reads and parses the stylesheet at path, colors may refer to vars */
+func loadStylesheet(path string, vars palette) (*stylesheet, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sheet, err := parseStylesheet(string(content), vars)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return sheet, nil
+}
+
+/* parseStylesheet parses the rules in text */
+func parseStylesheet(text string, vars palette) (*stylesheet, error) {
+	text, err := stripComments(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var sheet stylesheet
+	fonts := make(map[string]font.Face)
+	vars = maps.Clone(vars)
+	if vars == nil {
+		vars = make(palette)
+	}
+	for {
+		/* variables defined before the next rule */
+		for {
+			def, ok := strings.CutPrefix(strings.TrimSpace(text), "@define-color")
+			if !ok {
+				break
+			}
+			def, rest, ok := strings.Cut(def, ";")
+			if !ok {
+				return nil, fmt.Errorf("expected ';' after @define-color%s", def)
+			}
+			name, value, ok := strings.Cut(strings.TrimSpace(def), " ")
+			if !ok || name == "" {
+				return nil, fmt.Errorf("expected name and value after @define-color: %s", def)
+			}
+			if _, err := vars.parse(value); err != nil {
+				return nil, fmt.Errorf("@define-color %s: %w", name, err)
+			}
+			vars[name] = strings.TrimSpace(value)
+			text = rest
+		}
+
+		head, rest, ok := strings.Cut(text, "{")
+		if !ok {
+			if strings.TrimSpace(text) != "" {
+				return nil, fmt.Errorf("expected '{' after %q", strings.TrimSpace(text))
+			}
+			break
+		}
+		body, rest, ok := strings.Cut(rest, "}")
+		if !ok {
+			return nil, fmt.Errorf("expected '}' after %q", strings.TrimSpace(head))
+		}
+		text = rest
+
+		st, err := parseDeclarations(body, fonts, vars)
+		if err != nil {
+			return nil, err
+		}
+		for sel := range strings.SplitSeq(head, ",") {
+			selector, err := parseSelector(strings.TrimSpace(sel))
+			if err != nil {
+				return nil, err
+			}
+			if err := selector.check(st); err != nil {
+				return nil, fmt.Errorf("%s: %w", strings.TrimSpace(sel), err)
+			}
+			sheet.rules = append(sheet.rules, rule{selector, st})
+		}
+	}
+
+	/* stable, so later rules stay behind earlier ones of equal specificity */
+	slices.SortStableFunc(sheet.rules, func(a, b rule) int {
+		return a.selector.specificity() - b.selector.specificity()
+	})
+	return &sheet, nil
+}
+
+/* remove the comments from CSS text */
+func stripComments(text string) (string, error) {
+	for {
+		start := strings.Index(text, "/*")
+		if start == -1 {
+			return text, nil
+		}
+		end := strings.Index(text[start:], "*/")
+		if end == -1 {
+			return "", fmt.Errorf("unterminated comment")
+		}
+		text = text[:start] + text[start+end+2:]
+	}
+}
+
+/* parse a selector like "item.danger:hover" */
+func parseSelector(text string) (selector, error) {
+	sel := selector{depth: -1}
+	if text == "" {
+		return sel, fmt.Errorf("empty selector")
+	}
+	end := strings.IndexAny(text, ".:")
+	if end == -1 {
+		end = len(text)
+	}
+	switch kind := text[:end]; kind {
+	case "*", "":
+		/* any kind */
+	case "item", "header", "separator", "menu":
+		sel.kind = kind
+	default:
+		return sel, fmt.Errorf("unknown type in selector: %s", text)
+	}
+	text = text[end:]
+
+	for text != "" {
+		prefix := text[0]
+		text = text[1:]
+		end := strings.IndexAny(text, ".:")
+		if end == -1 {
+			end = len(text)
+		}
+		name := text[:end]
+		text = text[end:]
+		if name == "" {
+			return sel, fmt.Errorf("empty name in selector")
+		}
+		switch {
+		case prefix == '.':
+			sel.classes = append(sel.classes, name)
+		case name == "hover", name == "disabled", name == "checked":
+			sel.states = append(sel.states, name)
+		case strings.HasPrefix(name, "depth(") && strings.HasSuffix(name, ")"):
+			depth, err := strconv.Atoi(name[len("depth(") : len(name)-1])
+			if err != nil {
+				return sel, fmt.Errorf("invalid depth: %s", name)
+			}
+			sel.depth = depth
+		default:
+			return sel, fmt.Errorf("unknown state: %s", name)
+		}
+	}
+	return sel, nil
+}
+
+/* parse the declarations "name: value;" of a rule */
+func parseDeclarations(body string, fonts map[string]font.Face, vars palette) (style, error) {
+	var st style
+	for decl := range strings.SplitSeq(body, ";") {
+		if strings.TrimSpace(decl) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(decl, ":")
+		if !ok {
+			return st, fmt.Errorf("expected ':' in %q", strings.TrimSpace(decl))
+		}
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch name {
+		case "background":
+			st.background, err = vars.parse(value)
+		case "foreground", "color":
+			st.foreground, err = vars.parse(value)
+		case "border":
+			st.border, err = vars.parse(value)
+		case "padding":
+			st.padding, err = parsePadding(value)
+		case "font":
+			if fonts[value] == nil {
+				fonts[value], err = parseFontString(value)
+			}
+			st.font = fonts[value]
+		default:
+			err = fmt.Errorf("unknown property: %s", name)
+		}
+		if err != nil {
+			return st, err
+		}
+	}
+	return st, nil
+}
+
+/* parse a padding of "all" or "vertical horizontal" pixels */
+func parsePadding(value string) (*image.Point, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 1 || len(fields) > 2 {
+		return nil, fmt.Errorf("invalid padding: %s", value)
+	}
+	var n [2]int
+	for i, f := range fields {
+		var err error
+		n[i], err = strconv.Atoi(strings.TrimSuffix(f, "px"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid padding: %s", value)
+		}
+	}
+	if len(fields) == 1 {
+		n[1] = n[0]
+	}
+	return &image.Point{X: n[1], Y: n[0]}, nil
+}
+
+/* SetClass sets the classes the item is matched by in the stylesheet */
+func (item *Item[T]) SetClass(classes ...string) {
+	item.classes = classes
+	item.measure()
+	item.parent.itemsChanged = true
+}
+
+/* get the depth of menu, 0 for the root menu */
+func (menu *Menu[T]) depth() int {
+	if menu.owner == nil {
+		return 0
+	}
+	return menu.owner.parent.depth() + 1
+}
+
+/* describe item for matching against the stylesheet */
+func (item *Item[T]) selector(selected bool) selector {
+	target := selector{kind: "item", classes: item.classes, depth: item.parent.depth()}
+	switch {
+	case item.isSeparator():
+		target.kind = "separator"
+	case item.header:
+		target.kind = "header"
+	}
+	if selected {
+		target.states = append(target.states, "hover")
+	}
+	if item.disabled {
+		target.states = append(target.states, "disabled")
+	}
+	if item.checked {
+		target.states = append(target.states, "checked")
+	}
+	return target
+}
+
+/* resolve the style of item in the stylesheet */
+func (item *Item[T]) style(selected bool) style {
+	return item.parent.ctxmenu.stylesheet.resolve(item.selector(selected))
+}
+
+/* get the padding of item, the same in all states so the layout does not change with them */
+func (item *Item[T]) padding() image.Point {
+	if st := item.style(false); st.padding != nil {
+		return *st.padding
+	}
+	return image.Point{item.parent.ctxmenu.PaddingX, item.parent.ctxmenu.PaddingY}
+}
+
+/* get the colors of item, from the configuration overridden by the stylesheet */
+func (item *Item[T]) colors(selected bool) ColorPair {
+	ctxmenu := item.parent.ctxmenu
+	color := ctxmenu.normal
+	switch {
+	case selected:
+		color = ctxmenu.selected
+	case item.label == "":
+		color.Foreground = ctxmenu.separator
+	case item.disabled, item.separator:
+		color.Foreground = ctxmenu.disabled
+	case item.header:
+		color.Foreground = mixColor(color.Foreground, color.Background, 0.7)
+	}
+	st := item.style(selected)
+	if st.background != nil {
+		color.Background = st.background
+	}
+	if st.foreground != nil {
+		color.Foreground = st.foreground
+	}
+	return color
+}
+
+/* get the border color of menu */
+func (menu *Menu[T]) borderColor() *color.NRGBA {
+	st := menu.ctxmenu.stylesheet.resolve(selector{kind: "menu", depth: menu.depth()})
+	if st.border != nil {
+		return st.border
+	}
+	return menu.ctxmenu.border
+}
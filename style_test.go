@@ -0,0 +1,136 @@
+package ctxmenu
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		text string
+		want selector
+	}{
+		{"item", selector{kind: "item", depth: -1}},
+		{"*", selector{depth: -1}},
+		{".danger", selector{classes: []string{"danger"}, depth: -1}},
+		{"item.danger.bold:hover", selector{kind: "item", classes: []string{"danger", "bold"}, states: []string{"hover"}, depth: -1}},
+		{"header:disabled:checked", selector{kind: "header", states: []string{"disabled", "checked"}, depth: -1}},
+		{"menu:depth(1)", selector{kind: "menu", depth: 1}},
+	}
+	for _, test := range tests {
+		got, err := parseSelector(test.text)
+		if err != nil {
+			t.Errorf("parseSelector(%q): %v", test.text, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseSelector(%q) = %+v, want %+v", test.text, got, test.want)
+		}
+	}
+
+	for _, text := range []string{"", "button", "item.", "item:focus", "menu:depth(x)"} {
+		if _, err := parseSelector(text); err == nil {
+			t.Errorf("parseSelector(%q): expected an error", text)
+		}
+	}
+}
+
+func TestParsePadding(t *testing.T) {
+	tests := []struct {
+		text string
+		want image.Point
+	}{
+		{"4", image.Point{4, 4}},
+		{"6 8", image.Point{8, 6}},
+		{"6px 8px", image.Point{8, 6}},
+	}
+	for _, test := range tests {
+		got, err := parsePadding(test.text)
+		if err != nil {
+			t.Errorf("parsePadding(%q): %v", test.text, err)
+			continue
+		}
+		if *got != test.want {
+			t.Errorf("parsePadding(%q) = %v, want %v", test.text, *got, test.want)
+		}
+	}
+
+	for _, text := range []string{"", "1 2 3", "wide"} {
+		if _, err := parsePadding(text); err == nil {
+			t.Errorf("parsePadding(%q): expected an error", text)
+		}
+	}
+}
+
+func TestStylesheetResolve(t *testing.T) {
+	sheet, err := parseStylesheet(`
+		/* the order of the rules does not matter for specificity */
+		item.danger:hover { background: red; }
+		item.danger { background: maroon; }
+		* { background: black; }
+		item { background: gray; foreground: white; }
+		item { background: silver; }
+		:hover { background: blue; }
+		@define-color accent #3584e4;
+		menu:depth(1) { border: @accent; }
+		menu { border: @base; }
+	`, palette{"base": "#000000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nrgba := func(s string) *color.NRGBA {
+		c, err := parseColor(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	tests := []struct {
+		target     selector
+		background *color.NRGBA
+		foreground *color.NRGBA
+		border     *color.NRGBA
+	}{
+		{selector{kind: "item"}, nrgba("silver"), nrgba("white"), nil},
+		{selector{kind: "header"}, nrgba("black"), nil, nil},
+		{selector{kind: "item", states: []string{"hover"}}, nrgba("blue"), nrgba("white"), nil},
+		{selector{kind: "item", classes: []string{"danger"}}, nrgba("maroon"), nrgba("white"), nil},
+		{selector{kind: "item", classes: []string{"danger"}, states: []string{"hover"}}, nrgba("red"), nrgba("white"), nil},
+		{selector{kind: "menu", depth: 0}, nrgba("black"), nil, nrgba("#000000")},
+		{selector{kind: "menu", depth: 1}, nrgba("black"), nil, nrgba("#3584e4")},
+	}
+	for _, test := range tests {
+		got := sheet.resolve(test.target)
+		if !reflect.DeepEqual(got.background, test.background) || !reflect.DeepEqual(got.foreground, test.foreground) || !reflect.DeepEqual(got.border, test.border) {
+			t.Errorf("resolve(%+v) = %v %v %v, want %v %v %v", test.target,
+				got.background, got.foreground, got.border, test.background, test.foreground, test.border)
+		}
+	}
+}
+
+func TestParseStylesheetErrors(t *testing.T) {
+	for _, text := range []string{
+		"item { background: red; ",
+		"item { background: red; } /* unterminated",
+		"item { background red; }",
+		"item { margin: 4; }",
+		"item { border: red; }",
+		"menu { background: red; }",
+		"item:hover { padding: 4; }",
+		"item:checked { padding: 4; }",
+		".danger:disabled { padding: 4 8; }",
+		"item { background: @undefined; }",
+		"button { background: red; }",
+		"@define-color accent;",
+		"@define-color accent #fff",
+		"@define-color a @b; item { }",
+		"item",
+	} {
+		if _, err := parseStylesheet(text, nil); err == nil {
+			t.Errorf("parseStylesheet(%q): expected an error", text)
+		}
+	}
+}
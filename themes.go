@@ -0,0 +1,40 @@
+package ctxmenu
+
+import (
+	"maps"
+	"slices"
+)
+
+/* theme builds a color preset, the selected background is the theme variable @accent */
+func theme(background, foreground, accent, selforeground, separator, disabled, border string) Config {
+	return Config{
+		Colors:             map[string]string{"accent": accent},
+		BackgroundColor:    background,
+		ForegroundColor:    foreground,
+		SelbackgroundColor: "@accent",
+		SelforegroundColor: selforeground,
+		SeparatorColor:     separator,
+		DisabledColor:      disabled,
+		BorderColor:        border,
+	}
+}
+
+/* Themes holds the bundled color presets by name, apply them with Config.SetColors */
+var Themes = map[string]Config{
+	/*                              background foreground accent     selfg      separator  disabled   border */
+	"adwaita":         theme("#FFFFFF", "#2E3436", "#3584E4", "#FFFFFF", "#CDC7C2", "#929595", "#E6E6E6"),
+	"adwaita-dark":    theme("#383838", "#FFFFFF", "#3584E4", "#FFFFFF", "#505050", "#919191", "#1B1B1B"),
+	"breeze":          theme("#FCFCFC", "#232629", "#3DAEE9", "#FCFCFC", "#BDC3C7", "#7F8C8D", "#BDC3C7"),
+	"breeze-dark":     theme("#31363B", "#EFF0F1", "#3DAEE9", "#FCFCFC", "#4D5257", "#7F8C8D", "#1B1E20"),
+	"nord":            theme("#2E3440", "#D8DEE9", "#88C0D0", "#2E3440", "#4C566A", "#616E88", "#3B4252"),
+	"gruvbox":         theme("#282828", "#EBDBB2", "#D79921", "#282828", "#504945", "#928374", "#3C3836"),
+	"gruvbox-light":   theme("#FBF1C7", "#3C3836", "#458588", "#FBF1C7", "#D5C4A1", "#928374", "#EBDBB2"),
+	"solarized":       theme("#002B36", "#839496", "#268BD2", "#FDF6E3", "#073642", "#586E75", "#073642"),
+	"solarized-light": theme("#FDF6E3", "#657B83", "#268BD2", "#FDF6E3", "#EEE8D5", "#93A1A1", "#EEE8D5"),
+	"high-contrast":   theme("#000000", "#FFFFFF", "#FFFF00", "#000000", "#FFFFFF", "#A0A0A0", "#FFFFFF"),
+}
+
+/* ThemeNames returns the names of the bundled themes in alphabetical order */
+func ThemeNames() []string {
+	return slices.Sorted(maps.Keys(Themes))
+}
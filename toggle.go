@@ -0,0 +1,218 @@
+package ctxmenu
+
+import (
+	"image"
+	"math"
+	"slices"
+)
+
+type ItemKind int
+
+/* enum for the kind of an item */
+const (
+	KindNormal ItemKind = iota /* plain item */
+	KindCheck                  /* check-item, toggled when activated */
+	KindRadio                  /* radio-item, only one per group is checked */
+)
+
+/* Toggle is the final state of a check- or radio-item */
+type Toggle[T comparable] struct {
+	Output  T
+	Checked bool
+}
+
+/* Result is the outcome of Run */
+type Result[T comparable] struct {
+	Output   T           /* output of the activated item */
+	Toggles  []Toggle[T] /* state of all check- and radio-items, in menu order */
+	Selected []T         /* outputs of marked items in selection order, multi-select mode only */
+}
+
+/* SetCheckbox makes the item a check-item */
+func (item *Item[T]) SetCheckbox(checked bool) {
+	item.kind = KindCheck
+	item.checked = checked
+	item.parent.itemsChanged = true
+}
+
+/* SetRadio makes the item a radio-item in group, radio-items are grouped per menu */
+func (item *Item[T]) SetRadio(group string, checked bool) {
+	item.kind = KindRadio
+	item.group = group
+	item.checked = false
+	if checked {
+		item.parent.checkRadio(item)
+	}
+	item.parent.itemsChanged = true
+}
+
+/* Checked reports whether a check- or radio-item is checked */
+func (item *Item[T]) Checked() bool {
+	return item.checked
+}
+
+/* check a radio-item and uncheck all others in its group */
+func (menu *Menu[T]) checkRadio(item *Item[T]) {
+	for _, other := range menu.items {
+		if other.kind == KindRadio && other.group == item.group {
+			other.checked = false
+		}
+	}
+	item.checked = true
+}
+
+/* activate a leaf-item, toggling check- and radio-items; reports whether the menu should close */
+func (menu *Menu[T]) activate(index int) bool {
+	item := menu.items[index]
+	if item.target != nil {
+		/* results of the global search act on the item found */
+		if index := item.target.index(); index != -1 {
+			return item.target.parent.activate(index)
+		}
+		return true
+	}
+	switch item.kind {
+	case KindCheck:
+		item.checked = !item.checked
+	case KindRadio:
+		menu.checkRadio(item)
+	default:
+		if menu.ctxmenu.MultiSelect {
+			item.toggleMark()
+			return false
+		}
+		return true
+	}
+	return !menu.ctxmenu.KeepOpen
+}
+
+/* toggle the selection-mark of an item in multi-select mode */
+func (item *Item[T]) toggleMark() {
+	if item.target != nil {
+		item.target.toggleMark()
+		return
+	}
+	if item.mark != 0 {
+		item.mark = 0
+		return
+	}
+	item.parent.ctxmenu.marks++
+	item.mark = item.parent.ctxmenu.marks
+}
+
+/* collect the marked items in menu and its submenus */
+func (menu *Menu[T]) marked(list []*Item[T]) []*Item[T] {
+	for _, item := range menu.items {
+		if item.mark != 0 {
+			list = append(list, item)
+		}
+		if item.submenu != nil {
+			list = item.submenu.marked(list)
+		}
+	}
+	return list
+}
+
+/* get the outputs of all marked items in selection order */
+func (menu *Menu[T]) selection() []T {
+	items := menu.marked(nil)
+	slices.SortFunc(items, func(a, b *Item[T]) int {
+		return a.mark - b.mark
+	})
+	outputs := make([]T, len(items))
+	for i, item := range items {
+		outputs[i] = item.output
+	}
+	return outputs
+}
+
+/* collect the state of all check- and radio-items in menu and its submenus */
+func (menu *Menu[T]) toggles(list []Toggle[T]) []Toggle[T] {
+	for _, item := range menu.items {
+		if item.kind != KindNormal {
+			list = append(list, Toggle[T]{Output: item.output, Checked: item.checked})
+		}
+		if item.submenu != nil {
+			list = item.submenu.toggles(list)
+		}
+	}
+	return list
+}
+
+/* get the indicator matching the kind and state of item, nil if none is drawn */
+func (item *Item[T]) indicator() *image.Alpha {
+	ctxmenu := item.parent.ctxmenu
+	if item.target != nil {
+		return item.target.indicator()
+	}
+	if item.kind == KindNormal {
+		if item.mark != 0 {
+			return ctxmenu.selectionMark
+		}
+		return nil
+	}
+	state := 0
+	if item.checked {
+		state = 1
+	}
+	if item.kind == KindRadio {
+		return ctxmenu.radio[state]
+	}
+	return ctxmenu.checkbox[state]
+}
+
+/* outline of a check-mark in unit-coordinates */
+var checkMarkOutline = [][2]float32{
+	{0.20, 0.50}, {0.42, 0.70}, {0.78, 0.24}, {0.88, 0.34}, {0.42, 0.86}, {0.10, 0.60},
+}
+
+/* makeIndicator renders the check- or radio-indicator, or the selection-mark for KindNormal, sized relative to the font height */
+func (ctxmenu *ContextMenu) makeIndicator(kind ItemKind, checked bool) *image.Alpha {
+	size := max(int(math.Round(float64(ctxmenu.font.Metrics().Height.Ceil())*0.7)), 6)
+	stroke := max(float32(size)/10, 1) / float32(size)
+
+	var outlines [][][2]float32
+	switch kind {
+	case KindNormal:
+		if checked {
+			outlines = append(outlines, checkMarkOutline)
+		}
+	case KindCheck:
+		outlines = append(outlines,
+			rectOutline(0, 0, 1, 1, false),
+			rectOutline(stroke, stroke, 1-stroke, 1-stroke, true))
+		if checked {
+			outlines = append(outlines, checkMarkOutline)
+		}
+	case KindRadio:
+		outlines = append(outlines,
+			circleOutline(0.5, 0.5, 0.5, false),
+			circleOutline(0.5, 0.5, 0.5-stroke, true))
+		if checked {
+			outlines = append(outlines, circleOutline(0.5, 0.5, 0.25, false))
+		}
+	}
+	return rasterize(size, size, outlines...)
+}
+
+/* outline of a rectangle, reversed outlines punch holes */
+func rectOutline(x0, y0, x1, y1 float32, reverse bool) [][2]float32 {
+	if reverse {
+		return [][2]float32{{x0, y0}, {x0, y1}, {x1, y1}, {x1, y0}}
+	}
+	return [][2]float32{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}}
+}
+
+/* outline of a circle, reversed outlines punch holes */
+func circleOutline(cx, cy, r float32, reverse bool) [][2]float32 {
+	const segments = 32
+	outline := make([][2]float32, segments)
+	for i := range segments {
+		angle := 2 * math.Pi * float64(i) / segments
+		if reverse {
+			angle = -angle
+		}
+		outline[i] = [2]float32{cx + r*float32(math.Cos(angle)), cy + r*float32(math.Sin(angle))}
+	}
+	return outline
+}
@@ -0,0 +1,130 @@
+package ctxmenu
+
+import (
+	"image"
+	"image/draw"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/* tooltip is a small borderless window showing a single line of text */
+type tooltip struct {
+	win  *sdl.Window /* tooltip window, nil until first shown */
+	surf draw.Image  /* surface of win */
+	text string      /* text currently shown, empty if hidden */
+	x, y int         /* position */
+	w, h int         /* geometry */
+
+	/* tooltip waiting for the hover delay to pass */
+	pending  string
+	anchor   image.Rectangle
+	deadline time.Time
+}
+
+/* scheduleTooltip shows text near anchor once Config.TooltipDelay has passed */
+func (ctxmenu *ContextMenu) scheduleTooltip(text string, anchor image.Rectangle) {
+	tip := &ctxmenu.tooltip
+	if tip.pending == text && tip.anchor == anchor || tip.text == text && tip.anchor == anchor {
+		return
+	}
+	ctxmenu.hideTooltip()
+	if ctxmenu.TooltipDelay <= 0 {
+		ctxmenu.showTooltip(text, anchor)
+		return
+	}
+	tip.pending = text
+	tip.anchor = anchor
+	tip.deadline = time.Now().Add(ctxmenu.TooltipDelay)
+}
+
+/* pollTooltip shows the pending tooltip if its delay has passed */
+func (ctxmenu *ContextMenu) pollTooltip() {
+	tip := &ctxmenu.tooltip
+	if tip.pending == "" || time.Now().Before(tip.deadline) {
+		return
+	}
+	text := tip.pending
+	tip.pending = ""
+	ctxmenu.showTooltip(text, tip.anchor)
+}
+
+/* tooltipTimeout shortens timeout in milliseconds to the time left until the pending tooltip is due */
+func (ctxmenu *ContextMenu) tooltipTimeout(timeout int) int {
+	tip := &ctxmenu.tooltip
+	if tip.pending == "" {
+		return timeout
+	}
+	return max(min(timeout, int(time.Until(tip.deadline).Milliseconds())+1), 1)
+}
+
+/* showTooltip shows text below anchor, or above if there is no space, kept inside the display */
+func (ctxmenu *ContextMenu) showTooltip(text string, anchor image.Rectangle) error {
+	tip := &ctxmenu.tooltip
+	tip.pending = ""
+	if tip.text == text && tip.anchor == anchor {
+		return nil
+	}
+	tip.text = text
+	tip.anchor = anchor
+	tip.w = ctxmenu.messureText(text) + ctxmenu.PaddingX*2 + ctxmenu.BorderSize*2
+	tip.h = ctxmenu.font.Metrics().Height.Ceil() + ctxmenu.PaddingY*2 + ctxmenu.BorderSize*2
+
+	mr := ctxmenu.display
+	tip.x = anchor.Min.X
+	tip.y = anchor.Max.Y
+	if tip.y+tip.h > int(mr.Y+mr.H) {
+		tip.y = anchor.Min.Y - tip.h
+	}
+	tip.x = max(min(tip.x, int(mr.X+mr.W)-tip.w), int(mr.X))
+	tip.y = max(min(tip.y, int(mr.Y+mr.H)-tip.h), int(mr.Y))
+
+	var err error
+	if tip.win == nil {
+		tip.win, err = sdl.CreateWindow("tooltip", int32(tip.x), int32(tip.y), int32(tip.w), int32(tip.h), sdl.WINDOW_SHOWN|sdl.WINDOW_TOOLTIP)
+		if err != nil {
+			return err
+		}
+	} else {
+		tip.win.SetSize(int32(tip.w), int32(tip.h))
+		tip.win.SetPosition(int32(tip.x), int32(tip.y))
+		tip.win.Show()
+	}
+	/* the surface is invalidated by resizing */
+	tip.surf, err = tip.win.GetSurface()
+	if err != nil {
+		return err
+	}
+
+	bw := ctxmenu.BorderSize
+	draw.Draw(tip.surf, image.Rect(0, 0, tip.w, tip.h), image.NewUniform(ctxmenu.border), image.Point{}, draw.Src)
+	draw.Draw(tip.surf, image.Rect(bw, bw, tip.w-bw, tip.h-bw), image.NewUniform(opaque(ctxmenu.normal.Background)), image.Point{}, draw.Src)
+
+	textH := ctxmenu.font.Metrics().Height.Ceil()
+	mask := image.NewAlpha(image.Rect(0, 0, tip.w, textH))
+	ctxmenu.drawText(mask, text)
+	at := image.Point{bw + ctxmenu.PaddingX, bw + ctxmenu.PaddingY}
+	draw.DrawMask(tip.surf, mask.Bounds().Add(at), image.NewUniform(ctxmenu.normal.Foreground), image.Point{}, mask, image.Point{}, draw.Over)
+
+	return tip.win.UpdateSurface()
+}
+
+/* hideTooltip hides the tooltip window if shown and drops the pending tooltip */
+func (ctxmenu *ContextMenu) hideTooltip() {
+	tip := &ctxmenu.tooltip
+	tip.pending = ""
+	if tip.win == nil || tip.text == "" {
+		return
+	}
+	tip.text = ""
+	tip.win.Hide()
+}
+
+/* isTooltip reports whether win is the id of the tooltip window */
+func (ctxmenu *ContextMenu) isTooltip(win uint32) bool {
+	if ctxmenu.tooltip.win == nil {
+		return false
+	}
+	id, err := ctxmenu.tooltip.win.GetID()
+	return err == nil && id == win
+}